@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		expr string
+		want Rule
+	}{
+		{"cpu.total > 90 for 30s", Rule{Metric: "cpu.total", Op: ">", Threshold: 90, For: 30 * time.Second}},
+		{"memory.percent>=95", Rule{Metric: "memory.percent", Op: ">=", Threshold: 95}},
+		{"disk.write > 100M", Rule{Metric: "disk.write", Op: ">", Threshold: 100 << 20}},
+		{"battery.percent < 10%", Rule{Metric: "battery.percent", Op: "<", Threshold: 10}},
+		{`process "postgres" missing for 1m`, Rule{Process: "postgres", For: time.Minute}},
+		{`process "wait for me" missing`, Rule{Process: "wait for me"}},
+		{`process "wait for me" missing for 5s`, Rule{Process: "wait for me", For: 5 * time.Second}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", tt.expr, err)
+		}
+		tt.want.Expr = tt.expr
+		if got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseRule_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"cpu.bogus > 1",
+		"cpu.total > lots",
+		"cpu.total > 90 for soon",
+		"process postgres missing",
+		`process "postgres" running`,
+		`process "postgres" missing for soon`,
+	} {
+		if _, err := ParseRule(expr); err == nil {
+			t.Errorf("ParseRule(%q): expected error", expr)
+		}
+	}
+}
+
+func snapAt(at time.Time, cpu float64, procs ...string) Input {
+	snap := metrics.Snapshot{CollectedAt: at, CPU: metrics.CPUStats{Total: cpu, PerCore: []float64{cpu}}}
+	for i, name := range procs {
+		snap.Processes = append(snap.Processes, metrics.ProcessInfo{PID: int32(i + 1), Name: name})
+	}
+	return Input{Snapshot: snap}
+}
+
+func TestEngine_ForWindowAndResolve(t *testing.T) {
+	r, _ := ParseRule("cpu.total > 90 for 30s")
+	e := NewEngine([]Rule{r})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if ev := e.Evaluate(snapAt(start, 95)); len(ev) != 0 {
+		t.Fatalf("fired before the for window: %+v", ev)
+	}
+	if ev := e.Evaluate(snapAt(start.Add(20*time.Second), 95)); len(ev) != 0 {
+		t.Fatalf("fired before the for window: %+v", ev)
+	}
+	ev := e.Evaluate(snapAt(start.Add(30*time.Second), 96))
+	if len(ev) != 1 || ev[0].Status != StatusFiring || ev[0].Value != 96 || !ev[0].Since.Equal(start) {
+		t.Fatalf("expected firing event, got %+v", ev)
+	}
+	if len(e.Firing()) != 1 {
+		t.Fatalf("expected 1 firing alert, got %d", len(e.Firing()))
+	}
+	if ev := e.Evaluate(snapAt(start.Add(31*time.Second), 97)); len(ev) != 0 {
+		t.Fatalf("re-fired while already firing: %+v", ev)
+	}
+	ev = e.Evaluate(snapAt(start.Add(32*time.Second), 10))
+	if len(ev) != 1 || ev[0].Status != StatusResolved {
+		t.Fatalf("expected resolved event, got %+v", ev)
+	}
+	if len(e.Firing()) != 0 || len(e.History()) != 2 {
+		t.Fatalf("firing=%d history=%d, want 0 and 2", len(e.Firing()), len(e.History()))
+	}
+}
+
+func TestEngine_DipResetsWindow(t *testing.T) {
+	r, _ := ParseRule("cpu.total > 90 for 10s")
+	e := NewEngine([]Rule{r})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	e.Evaluate(snapAt(start, 95))
+	e.Evaluate(snapAt(start.Add(5*time.Second), 50))
+	if ev := e.Evaluate(snapAt(start.Add(11*time.Second), 95)); len(ev) != 0 {
+		t.Fatalf("dip below threshold should restart the window, got %+v", ev)
+	}
+}
+
+func TestEngine_ProcessMissing(t *testing.T) {
+	r, _ := ParseRule(`process "postgres" missing`)
+	e := NewEngine([]Rule{r})
+	now := time.Now()
+
+	if ev := e.Evaluate(snapAt(now, 1, "postgres", "bash")); len(ev) != 0 {
+		t.Fatalf("fired while process present: %+v", ev)
+	}
+	// An empty process list means processes were not collected.
+	if ev := e.Evaluate(snapAt(now.Add(time.Second), 1)); len(ev) != 0 {
+		t.Fatalf("fired without process data: %+v", ev)
+	}
+	if ev := e.Evaluate(snapAt(now.Add(2*time.Second), 1, "bash")); len(ev) != 1 || ev[0].Status != StatusFiring {
+		t.Fatalf("expected firing event, got %+v", ev)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "alerts.log")
+	outPath := filepath.Join(dir, "stdin.json")
+	r := Rule{Expr: "cpu.total > 1", Log: logPath, Command: "cat > " + outPath}
+	ev := Event{Name: "cpu", Rule: r.Expr, Status: StatusFiring, Value: 42}
+
+	if err := RunHooks(r, ev); err != nil {
+		t.Fatalf("RunHooks: %v", err)
+	}
+	if err := RunHooks(r, ev); err != nil {
+		t.Fatalf("RunHooks: %v", err)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(lines))
+	}
+	stdin, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Event
+	if err := json.Unmarshal(stdin, &got); err != nil {
+		t.Fatalf("command stdin is not JSON: %v", err)
+	}
+	if got.Name != "cpu" || got.Value != 42 || got.Status != StatusFiring {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	if err := RunHooks(Rule{Command: "exit 3"}, ev); err == nil {
+		t.Errorf("expected error from failing command")
+	}
+}
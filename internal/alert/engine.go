@@ -0,0 +1,140 @@
+package alert
+
+import (
+	"time"
+)
+
+// Status of an alert event.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Event is emitted when a rule starts or stops firing. It is also the
+// JSON payload handed to hooks.
+type Event struct {
+	Name      string    `json:"name"`
+	Rule      string    `json:"rule"`
+	Status    string    `json:"status"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold,omitempty"`
+	Since     time.Time `json:"since"`
+	At        time.Time `json:"at"`
+
+	rule int // index into Engine.rules
+}
+
+// Alert is a currently firing rule.
+type Alert struct {
+	Name   string
+	Rule   string
+	Metric string // empty for process rules
+	Value  float64
+	Since  time.Time
+}
+
+type ruleState struct {
+	pendingSince time.Time // when the condition first held; zero if it does not
+	firing       bool
+	firedAt      time.Time
+	value        float64
+}
+
+// historySize bounds the number of past events kept for display.
+const historySize = 50
+
+// Engine tracks rule state across snapshots. It is not safe for
+// concurrent use.
+type Engine struct {
+	rules   []Rule
+	state   []ruleState
+	history []Event
+}
+
+// NewEngine returns an engine for the given rules.
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{rules: rules, state: make([]ruleState, len(rules))}
+}
+
+// Rules returns the engine's rules.
+func (e *Engine) Rules() []Rule { return e.rules }
+
+// Evaluate checks every rule against in and returns the events for rules
+// that started or stopped firing. Time is taken from the snapshot so
+// replays evaluate `for` windows the same way live sessions do.
+func (e *Engine) Evaluate(in Input) []Event {
+	now := in.Snapshot.CollectedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var events []Event
+	for i, r := range e.rules {
+		st := &e.state[i]
+		v, ok, cond := r.holds(in)
+		if !ok {
+			continue // unknown this round; keep the current state
+		}
+		st.value = v
+		if !cond {
+			st.pendingSince = time.Time{}
+			if st.firing {
+				st.firing = false
+				events = append(events, e.event(i, *st, StatusResolved, now))
+			}
+			continue
+		}
+		if st.pendingSince.IsZero() {
+			st.pendingSince = now
+		}
+		if !st.firing && now.Sub(st.pendingSince) >= r.For {
+			st.firing = true
+			st.firedAt = now
+			events = append(events, e.event(i, *st, StatusFiring, now))
+		}
+	}
+	e.history = append(e.history, events...)
+	if over := len(e.history) - historySize; over > 0 {
+		e.history = append(e.history[:0], e.history[over:]...)
+	}
+	return events
+}
+
+func (e *Engine) event(i int, st ruleState, status string, now time.Time) Event {
+	r := e.rules[i]
+	return Event{
+		Name:      r.DisplayName(),
+		Rule:      r.Expr,
+		Status:    status,
+		Value:     st.value,
+		Threshold: r.Threshold,
+		Since:     st.pendingSince,
+		At:        now,
+		rule:      i,
+	}
+}
+
+// Rule returns the rule that produced ev.
+func (e *Engine) Rule(ev Event) Rule { return e.rules[ev.rule] }
+
+// Firing returns the currently firing alerts in rule order.
+func (e *Engine) Firing() []Alert {
+	var out []Alert
+	for i, r := range e.rules {
+		st := e.state[i]
+		if st.firing {
+			out = append(out, Alert{Name: r.DisplayName(), Rule: r.Expr, Metric: r.Metric, Value: st.value, Since: st.pendingSince})
+		}
+	}
+	return out
+}
+
+// History returns recent events, oldest first.
+func (e *Engine) History() []Event { return e.history }
+
+// DisplayName returns the rule's name, or its expression if unnamed.
+func (r Rule) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Expr
+}
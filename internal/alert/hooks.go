@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// hookTimeout bounds a single hook command.
+const hookTimeout = 10 * time.Second
+
+// RunHooks runs the command and log hooks configured on r for ev. The
+// command receives the event as JSON on stdin and in $HIDETOP_ALERT.
+func RunHooks(r Rule, ev Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep rule operators readable
+	if err := enc.Encode(ev); err != nil {
+		return err
+	}
+	payload := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	var errs []string
+	if r.Log != "" {
+		if err := appendLog(r.Log, payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if r.Command != "" {
+		if err := runCommand(r.Command, payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("alert %q: %s", ev.Name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func appendLog(path string, payload []byte) error {
+	path = expandHome(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(payload, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func runCommand(command string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "HIDETOP_ALERT="+string(payload))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("command: %w: %s", err, msg)
+		}
+		return fmt.Errorf("command: %w", err)
+	}
+	return nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
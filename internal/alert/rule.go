@@ -0,0 +1,261 @@
+// Package alert evaluates threshold rules against snapshots and runs
+// notification hooks when alerts fire or resolve.
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// Input is what rules are evaluated against: a snapshot plus the
+// throughput deltas the TUI computed for it.
+type Input struct {
+	Snapshot  metrics.Snapshot
+	NetDelta  metrics.NetworkDelta
+	DiskDelta metrics.DiskDelta
+}
+
+// Rule is a parsed alert rule. Either Metric or Process is set.
+type Rule struct {
+	Name string // display name; defaults to Expr
+	Expr string // source text, e.g. "cpu.total > 90 for 30s"
+
+	Metric    string  // e.g. "cpu.total"
+	Op        string  // one of > >= < <= == !=
+	Threshold float64 //
+	Process   string  // process name for `process "name" missing`
+
+	For time.Duration // condition must hold this long before firing
+
+	Command string // shell command run with the alert JSON on stdin
+	Log     string // file the alert JSON is appended to
+}
+
+// metricFuncs resolves metric names used in rules. The bool result is
+// false when the metric is not available in this snapshot, in which case
+// the rule neither fires nor resolves.
+var metricFuncs = map[string]func(Input) (float64, bool){
+	"cpu.total": func(in Input) (float64, bool) { return in.Snapshot.CPU.Total, len(in.Snapshot.CPU.PerCore) > 0 },
+	"cpu.max_core": func(in Input) (float64, bool) {
+		if len(in.Snapshot.CPU.PerCore) == 0 {
+			return 0, false
+		}
+		highest := 0.0
+		for _, v := range in.Snapshot.CPU.PerCore {
+			highest = max(highest, v)
+		}
+		return highest, true
+	},
+	"memory.percent": func(in Input) (float64, bool) { return in.Snapshot.Memory.Percent, in.Snapshot.Memory.Total > 0 },
+	"swap.percent": func(in Input) (float64, bool) {
+		return in.Snapshot.Memory.SwapPercent, in.Snapshot.Memory.SwapTotal > 0
+	},
+	"load.1":  func(in Input) (float64, bool) { return in.Snapshot.Load.Load1, true },
+	"load.5":  func(in Input) (float64, bool) { return in.Snapshot.Load.Load5, true },
+	"load.15": func(in Input) (float64, bool) { return in.Snapshot.Load.Load15, true },
+	"pressure.cpu": func(in Input) (float64, bool) {
+		return in.Snapshot.Pressure.CPU.Some.Avg10, in.Snapshot.Pressure.Available
+	},
+	"pressure.memory": func(in Input) (float64, bool) {
+		return in.Snapshot.Pressure.Memory.Some.Avg10, in.Snapshot.Pressure.Available
+	},
+	"pressure.io": func(in Input) (float64, bool) {
+		return in.Snapshot.Pressure.IO.Some.Avg10, in.Snapshot.Pressure.Available
+	},
+	"temperature.cpu": func(in Input) (float64, bool) {
+		return in.Snapshot.Temperature.CPUTemp, in.Snapshot.Temperature.CPUTemp > 0
+	},
+	"temperature.gpu": func(in Input) (float64, bool) {
+		return in.Snapshot.Temperature.GPUTemp, in.Snapshot.Temperature.GPUTemp > 0
+	},
+	"disk.root_percent": func(in Input) (float64, bool) { return in.Snapshot.Disk.RootPercent, in.Snapshot.Disk.RootTotalGB > 0 },
+	"disk.read":         func(in Input) (float64, bool) { return in.DiskDelta.ReadSec, in.DiskDelta.Available },
+	"disk.write":        func(in Input) (float64, bool) { return in.DiskDelta.WriteSec, in.DiskDelta.Available },
+	"network.in":        func(in Input) (float64, bool) { return in.NetDelta.TotalInSec, in.NetDelta.Available },
+	"network.out":       func(in Input) (float64, bool) { return in.NetDelta.TotalOutSec, in.NetDelta.Available },
+	"gpu.utilization": func(in Input) (float64, bool) {
+		g := in.Snapshot.GPU
+		return gpuValue(g != nil && g.Available, func() float64 { return g.Utilization })
+	},
+	"gpu.temperature": func(in Input) (float64, bool) {
+		g := in.Snapshot.GPU
+		return gpuValue(g != nil && g.Available && g.Temperature > 0, func() float64 { return g.Temperature })
+	},
+	"battery.percent": func(in Input) (float64, bool) { return in.Snapshot.Battery.Percent, in.Snapshot.Battery.Available },
+	"processes.count": func(in Input) (float64, bool) {
+		return float64(len(in.Snapshot.Processes)), len(in.Snapshot.Processes) > 0
+	},
+}
+
+func gpuValue(ok bool, v func() float64) (float64, bool) {
+	if !ok {
+		return 0, false
+	}
+	return v(), true
+}
+
+// MetricNames returns the metric names usable in rules, sorted.
+func MetricNames() []string {
+	names := make([]string, 0, len(metricFuncs))
+	for name := range metricFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var ops = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// ParseRule parses a rule expression:
+//
+//	<metric> <op> <number> [for <duration>]
+//	process "<name>" missing [for <duration>]
+//
+// Numbers accept a K/M/G suffix (powers of 1024) for byte rates, e.g.
+// `disk.write > 100M`.
+func ParseRule(expr string) (Rule, error) {
+	r := Rule{Expr: strings.TrimSpace(expr)}
+	body := r.Expr
+
+	if rest, ok := strings.CutPrefix(body, "process "); ok {
+		rest = strings.TrimSpace(rest)
+		name, tail, err := cutQuoted(rest)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+		}
+		// Only look for "for" after the name, which may contain it.
+		tail, r.For, err = cutFor(tail)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+		}
+		if strings.TrimSpace(tail) != "missing" {
+			return Rule{}, fmt.Errorf(`rule %q: expected 'process "name" missing'`, expr)
+		}
+		r.Process = name
+		return r, nil
+	}
+
+	body, d, err := cutFor(body)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+	}
+	r.For = d
+
+	for _, op := range ops {
+		idx := strings.Index(body, op)
+		if idx < 0 {
+			continue
+		}
+		r.Metric = strings.TrimSpace(body[:idx])
+		r.Op = op
+		v, err := parseNumber(strings.TrimSpace(body[idx+len(op):]))
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", expr, err)
+		}
+		r.Threshold = v
+		if _, ok := metricFuncs[r.Metric]; !ok {
+			return Rule{}, fmt.Errorf("rule %q: unknown metric %q (known: %s)", expr, r.Metric, strings.Join(MetricNames(), ", "))
+		}
+		return r, nil
+	}
+	return Rule{}, fmt.Errorf("rule %q: expected '<metric> <op> <value>' or 'process \"name\" missing'", expr)
+}
+
+// cutFor splits a trailing "for <duration>" from s.
+func cutFor(s string) (string, time.Duration, error) {
+	idx := strings.LastIndex(s, " for ")
+	if idx < 0 {
+		return s, 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s[idx+5:]))
+	if err != nil {
+		return "", 0, fmt.Errorf("bad duration: %w", err)
+	}
+	return strings.TrimSpace(s[:idx]), d, nil
+}
+
+// cutQuoted splits a leading double-quoted string from s.
+func cutQuoted(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("process name must be quoted")
+	}
+	end := strings.Index(s[1:], `"`)
+	if end < 0 {
+		return "", "", fmt.Errorf("unterminated process name")
+	}
+	return s[1 : end+1], s[end+2:], nil
+}
+
+func parseNumber(s string) (float64, error) {
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult, s = 1<<10, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1<<20, strings.TrimSuffix(s, "M")
+	case strings.HasSuffix(s, "G"):
+		mult, s = 1<<30, strings.TrimSuffix(s, "G")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad threshold %q", s)
+	}
+	return v * mult, nil
+}
+
+// holds reports whether the rule's condition is currently true, and
+// whether it could be evaluated at all.
+func (r Rule) holds(in Input) (value float64, ok, cond bool) {
+	if r.Process != "" {
+		if len(in.Snapshot.Processes) == 0 {
+			return 0, false, false
+		}
+		for _, p := range in.Snapshot.Processes {
+			if p.Name == r.Process {
+				return 1, true, false
+			}
+		}
+		return 0, true, true
+	}
+	v, ok := metricFuncs[r.Metric](in)
+	if !ok {
+		return 0, false, false
+	}
+	switch r.Op {
+	case ">":
+		cond = v > r.Threshold
+	case ">=":
+		cond = v >= r.Threshold
+	case "<":
+		cond = v < r.Threshold
+	case "<=":
+		cond = v <= r.Threshold
+	case "==":
+		cond = v == r.Threshold
+	case "!=":
+		cond = v != r.Threshold
+	}
+	return v, true, cond
+}
+
+// FromConfig parses the alert rules from the config file.
+func FromConfig(specs []config.AlertRule) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		r, err := ParseRule(spec.Rule)
+		if err != nil {
+			return nil, err
+		}
+		r.Name = spec.Name
+		r.Command = spec.Command
+		r.Log = spec.Log
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
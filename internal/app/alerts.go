@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/alert"
+)
+
+// alertHookMsg reports a failed alert hook.
+type alertHookMsg struct{ err error }
+
+// SetAlerts enables alert evaluation on every snapshot.
+func (m *Model) SetAlerts(e *alert.Engine) {
+	m.alerts = e
+}
+
+// evaluateAlerts checks the alert rules against the current snapshot and
+// returns a command running the hooks of rules that changed state.
+func (m Model) evaluateAlerts() tea.Cmd {
+	if m.alerts == nil {
+		return nil
+	}
+	events := m.alerts.Evaluate(alert.Input{Snapshot: m.snap, NetDelta: m.netDelta, DiskDelta: m.diskDelta})
+	var cmds []tea.Cmd
+	for _, ev := range events {
+		r := m.alerts.Rule(ev)
+		if r.Command == "" && r.Log == "" {
+			continue
+		}
+		cmds = append(cmds, func() tea.Msg {
+			return alertHookMsg{err: alert.RunHooks(r, ev)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleAlertHook surfaces hook failures in the status area.
+func (m Model) handleAlertHook(msg alertHookMsg) (tea.Model, tea.Cmd) {
+	if msg.err == nil {
+		return m, nil
+	}
+	m.killMsg = fmt.Sprintf("hook: %v", msg.err)
+	return m, tea.Tick(3*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// columnPickerState is the cursor of the open column picker.
+type columnPickerState struct {
+	cursor int
+}
+
+// handleColumnPickerKey edits m.columns from the picker overlay. The
+// last enabled column cannot be turned off.
+func (m Model) handleColumnPickerKey(key string) Model {
+	items := ui.ColumnPickerItems(m.columns)
+	cur := clampIndex(m.columnPicker.cursor, len(items))
+	item := items[cur]
+
+	switch key {
+	case "esc", "C", "q":
+		m.columnPicker = nil
+		return m
+	case "j", "down":
+		cur = clampIndex(cur+1, len(items))
+	case "k", "up":
+		cur = clampIndex(cur-1, len(items))
+	case " ", "enter":
+		if item.Enabled {
+			if len(m.columns) > 1 {
+				m.columns = slices.DeleteFunc(slices.Clone(m.columns), func(id string) bool { return id == item.ID })
+			}
+		} else {
+			m.columns = append(slices.Clone(m.columns), item.ID)
+			cur = len(m.columns) - 1 // follow it to the end of the enabled block
+		}
+	case "J", "K":
+		i := slices.Index(m.columns, item.ID)
+		j := i + 1
+		if key == "K" {
+			j = i - 1
+		}
+		if i >= 0 && j >= 0 && j < len(m.columns) {
+			m.columns = slices.Clone(m.columns)
+			m.columns[i], m.columns[j] = m.columns[j], m.columns[i]
+			cur = j
+		}
+	case "r":
+		m.columns = slices.Clone(ui.DefaultColumns)
+	}
+	m.columnPicker = &columnPickerState{cursor: cur}
+	return m
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/cpulist"
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/procctl"
+	"github.com/youhide/hideTop/internal/remote"
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// controlAction is a signal, renice or affinity change for the selected
+// process or every tagged process. It runs locally, or on the agent when
+// connected to one.
+type controlAction struct {
+	kind   string // remote.ActionSignal, ActionRenice or ActionAffinity
+	procs  []metrics.ProcessInfo
+	signal procctl.Signal
+	nice   int
+	cpus   []int
+}
+
+// target names the processes acted on: "PID 123" or "5 processes".
+func (a controlAction) target() string {
+	if len(a.procs) == 1 {
+		return fmt.Sprintf("PID %d", a.procs[0].PID)
+	}
+	return fmt.Sprintf("%d processes", len(a.procs))
+}
+
+// String describes the action, e.g. "send SIGHUP to PID 123".
+func (a controlAction) String() string {
+	switch a.kind {
+	case remote.ActionRenice:
+		return fmt.Sprintf("renice %s to %d", a.target(), a.nice)
+	case remote.ActionAffinity:
+		return fmt.Sprintf("pin %s to CPUs %s", a.target(), cpulist.Format(a.cpus))
+	}
+	return fmt.Sprintf("send SIG%s to %s", a.signal.Name(), a.target())
+}
+
+// done reports the action as completed.
+func (a controlAction) done() string {
+	switch a.kind {
+	case remote.ActionRenice:
+		return fmt.Sprintf("reniced %s to %d", a.target(), a.nice)
+	case remote.ActionAffinity:
+		return fmt.Sprintf("pinned %s to CPUs %s", a.target(), cpulist.Format(a.cpus))
+	}
+	return fmt.Sprintf("sent SIG%s to %s", a.signal.Name(), a.target())
+}
+
+// confirmText asks for y/N confirmation of the action.
+func (a controlAction) confirmText() string {
+	if len(a.procs) == 1 && a.procs[0].Name != "" {
+		return fmt.Sprintf("%s (%s)? (y/N)", a, a.procs[0].Name)
+	}
+	return fmt.Sprintf("%s? (y/N)", a)
+}
+
+// confirmItems lists the affected processes for the batch confirmation.
+func (a controlAction) confirmItems() []string {
+	items := make([]string, len(a.procs))
+	for i, p := range a.procs {
+		items[i] = fmt.Sprintf("%7d  %-10.10s %s", p.PID, p.User, p.Name)
+	}
+	return items
+}
+
+// controlResultMsg carries the status line for a finished action.
+type controlResultMsg struct{ text string }
+
+// signalMenuState is the open signal menu.
+type signalMenuState struct {
+	cursor int
+	target controlAction // the process the signal is for
+}
+
+// promptState is an open text prompt for a renice or affinity value.
+type promptState struct {
+	action controlAction // kind and process; its value is parsed from input
+	input  string
+}
+
+// label renders the prompt and its input for the status area.
+func (p promptState) label() string {
+	switch p.action.kind {
+	case remote.ActionRenice:
+		return fmt.Sprintf("nice for %s (%d..%d): %s▏", p.action.target(), procctl.MinNice, procctl.MaxNice, p.input)
+	default:
+		return fmt.Sprintf("CPUs for %s (e.g. 0-3,6): %s▏", p.action.target(), p.input)
+	}
+}
+
+// controlTargets returns the processes an action applies to: every
+// tagged process, or else the selected one.
+func (m Model) controlTargets() []metrics.ProcessInfo {
+	var procs []metrics.ProcessInfo
+	for _, p := range m.snap.Processes {
+		if m.isTagged(p) || (len(m.tagged) == 0 && p.PID == m.selectedPID) {
+			procs = append(procs, p)
+		}
+	}
+	slices.SortFunc(procs, func(a, b metrics.ProcessInfo) int { return int(a.PID - b.PID) })
+	return procs
+}
+
+// startControl handles the keys that act on the tagged or selected
+// processes: x and K ask to send SIGTERM and SIGKILL, X opens the signal
+// menu, and N and A prompt for a nice value and a CPU list.
+func (m Model) startControl(key string) Model {
+	procs := m.controlTargets()
+	if len(procs) == 0 {
+		return m
+	}
+	target := controlAction{kind: remote.ActionSignal, procs: procs}
+	single := len(procs) == 1
+	switch key {
+	case "x", "K":
+		target.signal = procctl.SIGTERM
+		if key == "K" {
+			target.signal = procctl.SIGKILL
+		}
+		m = m.askConfirm(target)
+	case "X":
+		m.signalMenu = &signalMenuState{target: target}
+	case "N":
+		target.kind = remote.ActionRenice
+		input := ""
+		if d, ok := m.details[procs[0].PID]; ok && single {
+			input = strconv.Itoa(int(d.Nice))
+		}
+		m.prompt = &promptState{action: target, input: input}
+	case "A":
+		target.kind = remote.ActionAffinity
+		input := ""
+		if m.remote == nil && single {
+			if cpus, err := procctl.Affinity(int(procs[0].PID)); err == nil {
+				input = cpulist.Format(cpus)
+			}
+		}
+		m.prompt = &promptState{action: target, input: input}
+	}
+	return m
+}
+
+// askConfirm asks for y/N confirmation of a: in the status area for one
+// process, or in an overlay listing every affected process.
+func (m Model) askConfirm(a controlAction) Model {
+	m.confirm = &a
+	m.killMsg = ""
+	if len(a.procs) == 1 {
+		m.killMsg = a.confirmText()
+	}
+	return m
+}
+
+// signalChoices lists the signals of the signal menu.
+func signalChoices() []ui.SignalChoice {
+	choices := make([]ui.SignalChoice, len(procctl.Signals))
+	for i, sig := range procctl.Signals {
+		choices[i] = ui.SignalChoice{Name: sig.Name(), Desc: sig.Description()}
+	}
+	return choices
+}
+
+// handleSignalMenuKey moves through the signal menu. Choosing a signal
+// asks for confirmation before it is sent.
+func (m Model) handleSignalMenuKey(key string) Model {
+	n := len(procctl.Signals)
+	cur := m.signalMenu.cursor
+	switch key {
+	case "esc", "X", "q":
+		m.signalMenu = nil
+		return m
+	case "j", "down":
+		cur = clampIndex(cur+1, n)
+	case "k", "up":
+		cur = clampIndex(cur-1, n)
+	case "enter", "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
+		if key != "enter" {
+			cur = (int(key[0]-'0') + 9) % 10 // "1" is the first, "0" the tenth
+			if cur >= n {
+				return m
+			}
+		}
+		a := m.signalMenu.target
+		a.signal = procctl.Signals[cur]
+		m.signalMenu = nil
+		return m.askConfirm(a)
+	}
+	m.signalMenu = &signalMenuState{cursor: cur, target: m.signalMenu.target}
+	return m
+}
+
+// handlePromptKey edits the renice or affinity prompt. Enter parses the
+// input and asks for confirmation.
+func (m Model) handlePromptKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := *m.prompt
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.prompt = nil
+		return m, nil
+	case tea.KeyBackspace:
+		if r := []rune(p.input); len(r) > 0 {
+			p.input = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes:
+		p.input += string(msg.Runes)
+	case tea.KeyEnter:
+		m.prompt = nil
+		a := p.action
+		var err error
+		if a.kind == remote.ActionRenice {
+			a.nice, err = strconv.Atoi(strings.TrimSpace(p.input))
+			if err != nil {
+				err = fmt.Errorf("bad nice value %q", p.input)
+			} else if a.nice < procctl.MinNice || a.nice > procctl.MaxNice {
+				err = fmt.Errorf("nice %d out of range %d..%d", a.nice, procctl.MinNice, procctl.MaxNice)
+			}
+		} else {
+			a.cpus, err = cpulist.Parse(p.input)
+		}
+		if err != nil {
+			m.killMsg = err.Error()
+			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+		}
+		return m.askConfirm(a), nil
+	}
+	m.prompt = &p
+	return m, nil
+}
+
+// runControl performs a confirmed action on each of its processes and
+// reports the outcome in the status area.
+func (m Model) runControl(a controlAction) tea.Cmd {
+	c := m.remote
+	return func() tea.Msg {
+		var failed int
+		var firstErr error
+		var firstPID int32
+		for _, p := range a.procs {
+			if err := runControlOne(c, a, p.PID); err != nil {
+				if failed == 0 {
+					firstErr, firstPID = err, p.PID
+				}
+				failed++
+			}
+		}
+		switch {
+		case failed == 0:
+			text := a.done()
+			if c != nil {
+				text += " on " + c.Hostname()
+			}
+			return controlResultMsg{text: text}
+		case len(a.procs) == 1:
+			return controlResultMsg{text: fmt.Sprintf("%s: %v", a, firstErr)}
+		default:
+			return controlResultMsg{text: fmt.Sprintf("%s: %d failed (PID %d: %v)", a, failed, firstPID, firstErr)}
+		}
+	}
+}
+
+// runControlOne performs a for pid, on the agent when c is non-nil.
+func runControlOne(c *remote.Client, a controlAction, pid int32) error {
+	if c == nil {
+		switch a.kind {
+		case remote.ActionRenice:
+			return procctl.Renice(int(pid), a.nice)
+		case remote.ActionAffinity:
+			return procctl.SetAffinity(int(pid), a.cpus)
+		default:
+			return procctl.Kill(int(pid), a.signal)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	switch a.kind {
+	case remote.ActionRenice:
+		return c.Renice(ctx, pid, a.nice)
+	case remote.ActionAffinity:
+		return c.SetAffinity(ctx, pid, a.cpus)
+	default:
+		return c.Signal(ctx, pid, a.signal.Name())
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// processDetailMsg carries a fresh read of the process in the detail
+// overlay. err is set when the process no longer exists.
+type processDetailMsg struct {
+	pid        int32
+	details    metrics.ProcessDetails
+	inspection metrics.ProcessInspection
+	err        error
+}
+
+// openDetail opens the detail overlay for the selected process. Replayed
+// and remote PIDs may belong to another process on this host, so only
+// their recorded fields are shown.
+func (m Model) openDetail() (Model, tea.Cmd) {
+	for _, p := range m.snap.Processes {
+		if p.PID == m.selectedPID {
+			m.showDetail = &ui.ProcessDetail{
+				ProcessInfo: p,
+				CPUHistory:  []float64{p.CPUPercent},
+				RSSHistory:  []float64{float64(p.RSS)},
+				Local:       m.replay == nil && m.remote == nil,
+			}
+			m.detailFetching = false
+			cmd := m.inspectDetail()
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// refreshDetail updates the open detail overlay from the new snapshot
+// and starts a new inspection of its process.
+func (m *Model) refreshDetail() tea.Cmd {
+	if m.showDetail == nil {
+		return nil
+	}
+	d := *m.showDetail
+	found := false
+	for _, p := range m.snap.Processes {
+		if p.PID == d.PID && (p.CreateTime == d.CreateTime || d.CreateTime == 0) {
+			details := d.ProcessDetails
+			d.ProcessInfo = p
+			if d.Local {
+				d.ProcessDetails = details // kept until the inspection refreshes them
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		d.Exited = true
+		m.showDetail = &d
+		return nil
+	}
+	d.CPUHistory = appendHistory(d.CPUHistory, d.CPUPercent)
+	d.RSSHistory = appendHistory(d.RSSHistory, float64(d.RSS))
+	m.showDetail = &d
+	return m.inspectDetail()
+}
+
+// inspectDetail reads the detail overlay's process in depth, unless a
+// read is already running or the process is not on this host.
+func (m *Model) inspectDetail() tea.Cmd {
+	d := m.showDetail
+	if d == nil || !d.Local || d.Exited || m.detailFetching {
+		return nil
+	}
+	m.detailFetching = true
+	pid, prev := d.PID, d.Inspection
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		in, err := metrics.InspectProcess(ctx, pid, prev)
+		if err != nil {
+			return processDetailMsg{pid: pid, err: err}
+		}
+		return processDetailMsg{pid: pid, details: metrics.ReadProcessDetails(ctx, pid), inspection: in}
+	}
+}
+
+func (m Model) handleProcessDetail(msg processDetailMsg) (tea.Model, tea.Cmd) {
+	m.detailFetching = false
+	if m.showDetail == nil || m.showDetail.PID != msg.pid {
+		return m, nil
+	}
+	d := *m.showDetail
+	if msg.err != nil {
+		d.Exited = true
+	} else {
+		d.ProcessDetails = msg.details
+		d.Inspection = msg.inspection
+	}
+	m.showDetail = &d
+	return m, nil
+}
+
+// handleDetailKey switches tabs and scrolls the detail overlay.
+func (m Model) handleDetailKey(key string) Model {
+	d := *m.showDetail
+	tabs := len(ui.DetailTabs)
+	w, h := m.width, m.height
+	if w == 0 {
+		w = 80
+	}
+	if h == 0 {
+		h = 24
+	}
+	switch key {
+	case "esc", "enter", "q":
+		m.showDetail = nil
+		return m
+	case "tab", "right", "l":
+		d.Tab = (d.Tab + 1) % tabs
+		d.Scroll = 0
+	case "shift+tab", "left", "h":
+		d.Tab = (d.Tab - 1 + tabs) % tabs
+		d.Scroll = 0
+	case "1", "2", "3", "4", "5", "6":
+		if t := int(key[0] - '1'); t < tabs {
+			d.Tab = t
+			d.Scroll = 0
+		}
+	case "j", "down":
+		d.Scroll++
+	case "k", "up":
+		d.Scroll--
+	case "pgdown", " ":
+		d.Scroll += 10
+	case "pgup":
+		d.Scroll -= 10
+	case "g", "home":
+		d.Scroll = 0
+	case "G", "end":
+		d.Scroll = ui.DetailMaxScroll(d, w, h)
+	}
+	d.Scroll = max(0, min(d.Scroll, ui.DetailMaxScroll(d, w, h)))
+	m.showDetail = &d
+	return m
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// processDetailsMsg carries the details read for shown process rows.
+type processDetailsMsg struct {
+	sample  time.Time // process sample the rows were shown for
+	details map[int32]metrics.ProcessDetails
+}
+
+// detailsCmd reads the ProcessDetails of shown rows that have none for
+// the current process sample. The live collector reads no details, so
+// columns such as COMMAND are only filled here, for the rows on screen.
+// Remote and replayed snapshots carry their own details. A search filter
+// on a detail field needs them for every process.
+func (m *Model) detailsCmd() tea.Cmd {
+	if m.fetchingDetails || m.remote != nil || m.replay != nil {
+		return nil
+	}
+	rows := m.snap.Processes
+	if m.filter == nil || !m.filter.NeedsDetails() {
+		if m.groupBy != metrics.GroupNone || !ui.NeedsDetails(m.columns) {
+			return nil
+		}
+		rows = m.visibleProcesses()
+	}
+	sample := m.snap.SampledAt["proc"]
+	var pids []int32
+	for _, p := range rows {
+		if _, ok := m.details[p.PID]; !ok || !m.detailsSample.Equal(sample) {
+			pids = append(pids, p.PID)
+		}
+	}
+	if len(pids) == 0 {
+		return nil
+	}
+	m.fetchingDetails = true
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		details := make(map[int32]metrics.ProcessDetails, len(pids))
+		for _, pid := range pids {
+			details[pid] = metrics.ReadProcessDetails(ctx, pid)
+		}
+		return processDetailsMsg{sample: sample, details: details}
+	}
+}
+
+// handleProcessDetails stores read details. Details of an older sample
+// are replaced rather than merged, so refreshed rows do not mix samples.
+func (m Model) handleProcessDetails(msg processDetailsMsg) (tea.Model, tea.Cmd) {
+	m.fetchingDetails = false
+	if m.details == nil || !msg.sample.Equal(m.detailsSample) {
+		m.details = msg.details
+		m.detailsSample = msg.sample
+	} else {
+		maps.Copy(m.details, msg.details)
+	}
+	return withDetailsCmd(m, nil)
+}
+
+// withDetails returns procs with the details read so far filled in.
+func (m Model) withDetails(procs []metrics.ProcessInfo) []metrics.ProcessInfo {
+	if len(m.details) == 0 {
+		return procs
+	}
+	out := make([]metrics.ProcessInfo, len(procs))
+	for i, p := range procs {
+		if d, ok := m.details[p.PID]; ok {
+			p.ProcessDetails = d
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// visibleProcesses returns the process rows currently on screen.
+func (m Model) visibleProcesses() []metrics.ProcessInfo {
+	procs := m.filteredProcesses()
+	selectedIdx, _ := findSelectionIndex(m.selectedPID, m.rows(), m.lastSelectedIdx)
+	state := ui.ProcessViewState{SelectedIdx: selectedIdx, TreeView: m.treeView, Collapsed: m.collapsed}
+	return ui.VisibleProcesses(procs, state, m.procRows())
+}
+
+// procRows returns the number of process rows that fit on screen.
+func (m Model) procRows() int {
+	h := m.height
+	if h == 0 {
+		h = 24
+	}
+	emptyProc := ui.RenderProcesses(nil, ui.ProcessViewState{}, m.width, 0)
+	procOverhead := strings.Count(emptyProc, "\n") + 1
+	rows := h - m.computeUsedLines() - procOverhead
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+// withDetailsCmd batches cmd with a read of the details of rows that
+// came into view. detailsCmd marks the read as in flight on m, so it
+// must run before m is copied into the result.
+func withDetailsCmd(tm tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m, ok := tm.(Model)
+	if !ok {
+		return tm, cmd
+	}
+	detailsCmd := m.detailsCmd()
+	return m, tea.Batch(cmd, detailsCmd)
+}
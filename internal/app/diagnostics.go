@@ -0,0 +1,67 @@
+package app
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/procctl"
+	"github.com/youhide/hideTop/internal/remote"
+)
+
+// diagViewState is the cursor of the open diagnostics overlay.
+type diagViewState struct {
+	cursor int
+}
+
+// handleDiagKey moves through the diagnostics overlay. X opens the
+// signal menu for a zombie's parent, which is what can reap it, or for
+// the process itself on other rows. Enter selects the process in the
+// table.
+func (m Model) handleDiagKey(key string) (Model, tea.Cmd) {
+	n := m.diag.Count()
+	cur := clampIndex(m.diagView.cursor, n)
+	switch key {
+	case "esc", "D", "q":
+		m.diagView = nil
+		return m, nil
+	case "j", "down":
+		cur = clampIndex(cur+1, n)
+	case "k", "up":
+		cur = clampIndex(cur-1, n)
+	case "enter":
+		if p, ok := m.diag.At(cur); ok {
+			m.diagView = nil
+			m.setSearchQuery("")
+			m.groupBy = metrics.GroupNone
+			m.groupFilter = ""
+			m.selectedPID = p.Process.PID
+			return m, nil
+		}
+	case "X":
+		p, ok := m.diag.At(cur)
+		if !ok {
+			break
+		}
+		if m.replay != nil {
+			m.killMsg = "not available in replay"
+			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+		}
+		target, menuCursor := p.Process, 0
+		if metrics.StateLetter(p.Process.State) == "Z" {
+			if p.Parent.PID == 0 {
+				break
+			}
+			target = p.Parent
+			menuCursor = max(slices.IndexFunc(procctl.Signals, func(s procctl.Signal) bool { return s.Name() == "CHLD" }), 0)
+		}
+		m.signalMenu = &signalMenuState{
+			cursor: menuCursor,
+			target: controlAction{kind: remote.ActionSignal, procs: []metrics.ProcessInfo{target}},
+		}
+	}
+	m.diagView = &diagViewState{cursor: cur}
+	return m, nil
+}
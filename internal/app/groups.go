@@ -0,0 +1,75 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// groups returns the aggregated rows shown in grouping mode.
+func (m Model) groups() []metrics.ProcessGroup {
+	return metrics.GroupProcesses(m.filteredProcesses(), m.groupBy, m.sortBy, m.sortReverse)
+}
+
+// byUser reports whether processes are grouped by user or a user's
+// group is open. The system user filter is ignored then, so the summary
+// covers the whole process table.
+func (m Model) byUser() bool {
+	return m.groupBy == metrics.GroupUser || (m.groupFilter != "" && m.groupFilterBy == metrics.GroupUser)
+}
+
+// handleGroupKey handles keys that act on group rows. Enter drills into
+// the selected group, showing its processes; Esc returns to the groups.
+// Process actions are swallowed since a group row is not a process.
+func (m Model) handleGroupKey(key string) (Model, tea.Cmd, bool) {
+	groups := m.groups()
+	switch key {
+	case "j", "down":
+		m.selectGroup(groups, m.groupIndex(groups)+1)
+	case "k", "up":
+		m.selectGroup(groups, m.groupIndex(groups)-1)
+	case "enter":
+		if len(groups) == 0 {
+			return m, nil, true
+		}
+		m.groupFilter = groups[m.groupIndex(groups)].Key
+		m.groupFilterBy = m.groupBy
+		m.groupBy = metrics.GroupNone
+		m.selectedPID = 0
+	case "x", "K", "X", "N", "A", " ", "*":
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// groupIndex resolves the selected group key to a row, staying at the
+// last known row if the group disappeared.
+func (m Model) groupIndex(groups []metrics.ProcessGroup) int {
+	for i, g := range groups {
+		if g.Key == m.selectedGroup {
+			return i
+		}
+	}
+	return clampIndex(m.groupIdx, len(groups))
+}
+
+// selectGroup selects the group at row i, clamped to the list.
+func (m *Model) selectGroup(groups []metrics.ProcessGroup, i int) {
+	if len(groups) == 0 {
+		return
+	}
+	m.groupIdx = clampIndex(i, len(groups))
+	m.selectedGroup = groups[m.groupIdx].Key
+}
+
+// clampIndex clamps i to [0, n).
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// eventsViewState is the state of the open process events overlay.
+type eventsViewState struct {
+	cursor int
+	kind   string // "" for all, else metrics.ProcessStarted or ProcessExited
+	byCPU  bool   // order by peak CPU instead of newest first
+}
+
+// label describes the filter and order for the overlay title.
+func (v eventsViewState) label() string {
+	s := "all"
+	switch v.kind {
+	case metrics.ProcessStarted:
+		s = "starts"
+	case metrics.ProcessExited:
+		s = "exits"
+	}
+	if v.byCPU {
+		return s + " by peak CPU"
+	}
+	return s + ", newest first"
+}
+
+// eventRows returns the logged process events the overlay shows.
+func (m Model) eventRows() []metrics.ProcessEvent {
+	log := m.snap.ProcessEvents
+	rows := make([]metrics.ProcessEvent, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		if m.eventsView.kind == "" || log[i].Kind == m.eventsView.kind {
+			rows = append(rows, log[i])
+		}
+	}
+	if m.eventsView.byCPU {
+		slices.SortStableFunc(rows, func(a, b metrics.ProcessEvent) int {
+			switch {
+			case a.PeakCPU > b.PeakCPU:
+				return -1
+			case a.PeakCPU < b.PeakCPU:
+				return 1
+			}
+			return 0
+		})
+	}
+	return rows
+}
+
+// handleEventsKey moves through the process events overlay. Enter
+// selects the process in the table if it is still running.
+func (m Model) handleEventsKey(key string) (Model, tea.Cmd) {
+	v := *m.eventsView
+	switch key {
+	case "esc", "L", "q":
+		m.eventsView = nil
+		return m, nil
+	case "j", "down":
+		v.cursor++
+	case "k", "up":
+		v.cursor--
+	case "f":
+		switch v.kind {
+		case "":
+			v.kind = metrics.ProcessStarted
+		case metrics.ProcessStarted:
+			v.kind = metrics.ProcessExited
+		default:
+			v.kind = ""
+		}
+		v.cursor = 0
+	case "c":
+		v.byCPU = !v.byCPU
+		v.cursor = 0
+	case "enter":
+		rows := m.eventRows()
+		if len(rows) == 0 {
+			break
+		}
+		ev := rows[clampIndex(v.cursor, len(rows))]
+		if i := slices.IndexFunc(m.snap.Processes, func(p metrics.ProcessInfo) bool {
+			return p.PID == ev.PID && ev.Kind == metrics.ProcessStarted && p.CreateTime == ev.Time.UnixMilli()
+		}); i < 0 {
+			m.killMsg = fmt.Sprintf("PID %d is no longer running", ev.PID)
+			m.eventsView = &v
+			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+		}
+		m.eventsView = nil
+		m.setSearchQuery("")
+		m.groupBy = metrics.GroupNone
+		m.groupFilter = ""
+		m.selectedPID = ev.PID
+		return m, nil
+	}
+	m.eventsView = &v
+	m.eventsView.cursor = clampIndex(v.cursor, len(m.eventRows()))
+	return m, nil
+}
@@ -3,17 +3,22 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/shirou/gopsutil/v4/process"
 
+	"github.com/youhide/hideTop/internal/alert"
 	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/filter"
 	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/record"
+	"github.com/youhide/hideTop/internal/remote"
 	"github.com/youhide/hideTop/internal/ui"
 )
 
@@ -25,11 +30,6 @@ type flashDoneMsg struct{}
 
 type killMsgClearMsg struct{}
 
-type processDetailMsg struct {
-	detail ui.ProcessDetail
-	err    error
-}
-
 // historySize is the max number of samples kept for sparklines.
 const historySize = 60
 
@@ -40,16 +40,24 @@ type Model struct {
 	netDelta      metrics.NetworkDelta
 	diskDelta     metrics.DiskDelta
 	sortBy        metrics.SortField
+	sortReverse   bool // sortBy in the reverse of its default direction
 	width         int
 	height        int
 	quitting      bool
 	selectedPID   int32
 	searching     bool
 	searchQuery   string
+	filter        *filter.Filter // parsed searchQuery; nil = no filter
+	filterErr     string         // parse error of searchQuery, if any
 	refreshFlash  bool
 	collecting    bool
 	collectCancel context.CancelFunc
 
+	// Details of shown process rows, read lazily (see detailsCmd)
+	details         map[int32]metrics.ProcessDetails
+	detailsSample   time.Time // process sample details were read for
+	fetchingDetails bool
+
 	// Sparkline history
 	cpuHistory []float64
 	memHistory []float64
@@ -58,18 +66,60 @@ type Model struct {
 	// UI state
 	showHelp        bool
 	showDetail      *ui.ProcessDetail // non-nil = showing detail overlay
+	detailFetching  bool              // an inspection of showDetail is running
+	memExpanded     bool              // memory panel shows the full breakdown
 	treeView        bool
+	collapsed       map[int32]bool  // tree view: PIDs with folded subtrees
+	tagged          map[int32]int64 // PID → create time of processes tagged for batch actions
 	hideSystem      bool
-	confirmKill     killSignal // non-zero = awaiting Y/N confirmation
-	killMsg         string     // status message after kill attempt
-	lastSelectedIdx int        // last known visual index for fallback
+	confirm         *controlAction   // non-nil = awaiting y/N confirmation
+	signalMenu      *signalMenuState // non-nil = signal menu open
+	prompt          *promptState     // non-nil = reading a nice value or CPU list
+	killMsg         string           // status message after a process action
+	lastSelectedIdx int              // last known visual index for fallback
 	version         string
+
+	// Process table columns
+	columns      []string
+	columnPicker *columnPickerState // non-nil = picker overlay open
+
+	// Process grouping
+	groupBy       metrics.GroupBy // non-zero = showing aggregated groups
+	selectedGroup string          // key of the selected group row
+	groupIdx      int             // last known group row, for fallback
+	groupFilter   string          // non-empty = drilled into this group
+	groupFilterBy metrics.GroupBy // grouping mode groupFilter belongs to
+
+	// Session recording and replay
+	recorder *recorder    // non-nil = append every snapshot
+	replay   *replayState // non-nil = replaying a recording
+
+	// Remote agent connection
+	remote    *remote.Client // non-nil = rendering an agent's snapshots
+	remoteErr error          // set once the agent connection is lost
+
+	// Alerting
+	alerts     *alert.Engine // nil = no rules configured
+	showAlerts bool
+
+	// Zombie, D-state and reparenting diagnostics
+	diag     metrics.Diagnostics
+	diagView *diagViewState // non-nil = diagnostics overlay open
+
+	eventsView *eventsViewState // non-nil = process events overlay open
 }
 
 func New(cfg config.Config) Model {
+	columns := cfg.Columns
+	if len(columns) == 0 {
+		columns = slices.Clone(ui.DefaultColumns)
+	}
 	return Model{
-		cfg:    cfg,
-		sortBy: metrics.SortByCPU,
+		cfg:     cfg,
+		sortBy:  metrics.SortByCPU,
+		columns: columns,
+		// The baseline of the first snapshot's CPU percentages.
+		snap: metrics.PrimeCPU(context.Background()),
 	}
 }
 
@@ -79,7 +129,14 @@ func (m *Model) SetVersion(v string) {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tick(m.cfg.RefreshInterval)
+	if m.replay != nil {
+		return m.replayTick()
+	}
+	if m.remote != nil {
+		return waitForRemote(m.remote)
+	}
+	// The first snapshot only waits for the CPU baseline to age.
+	return tick(min(m.cfg.RefreshInterval, metrics.CPUPrimeWindow))
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -87,24 +144,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		return m, nil
+		return withDetailsCmd(m, nil)
 
 	case tea.KeyMsg:
-		return m.handleKey(msg)
+		return withDetailsCmd(m.handleKey(msg))
 
 	case tea.MouseMsg:
-		return m.handleMouse(msg)
+		return withDetailsCmd(m.handleMouse(msg))
+
+	case processDetailsMsg:
+		return m.handleProcessDetails(msg)
+
+	case replayTickMsg:
+		return m.handleReplayTick(msg)
+
+	case remoteClosedMsg:
+		m.remoteErr = msg.err
+		return m, nil
+
+	case alertHookMsg:
+		return m.handleAlertHook(msg)
+
+	case controlResultMsg:
+		m.killMsg = msg.text
+		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
 
 	case tickMsg:
 		cmds := []tea.Cmd{tick(m.cfg.RefreshInterval)}
 		if !m.collecting {
-			ctx, cancel := context.WithTimeout(context.Background(), m.collectionTimeout())
+			ctx, cancel := context.WithTimeout(context.Background(), m.cfg.CollectionTimeout())
 			m.collectCancel = cancel
 			m.collecting = true
-			cmds = append(cmds, collectSnapshot(ctx, m.sortBy, m.snap, m.processSampleEvery(), m.cfg.ProcLimit, metrics.CollectOptions{
-				SkipGPU:  m.cfg.NoGPU,
-				SkipTemp: m.cfg.NoTemp,
-			}))
+			cmds = append(cmds, collectSnapshot(ctx, m.sortBy, m.snap, m.cfg.ProcessSampleEvery(), m.collectOptions()))
 		}
 		return m, tea.Batch(cmds...)
 
@@ -121,16 +192,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.netDelta = metrics.NetworkDelta{}
 			m.diskDelta = metrics.DiskDelta{}
 		} else {
-			interval := newSnap.CollectedAt.Sub(m.prevSnap.CollectedAt).Seconds()
-			m.netDelta = metrics.ComputeNetworkDelta(newSnap.Network, m.prevSnap.Network, interval)
-			m.diskDelta = metrics.ComputeDiskDelta(newSnap.Disk, m.prevSnap.Disk, interval)
+			// Collectors may run slower than the tick; only recompute a
+			// delta when its counters were actually resampled.
+			if interval := sampleInterval(newSnap, m.prevSnap, "net"); interval > 0 {
+				m.netDelta = metrics.ComputeNetworkDelta(newSnap.Network, m.prevSnap.Network, interval)
+			}
+			if interval := sampleInterval(newSnap, m.prevSnap, "disk"); interval > 0 {
+				m.diskDelta = metrics.ComputeDiskDelta(newSnap.Disk, m.prevSnap.Disk, interval)
+			}
 		}
 
 		m.prevSnap = m.snap
 		m.snap = newSnap
 
+		var recordCmd tea.Cmd
+		if m.recorder != nil {
+			err := m.recorder.write(record.Frame{Snapshot: newSnap, NetDelta: m.netDelta, DiskDelta: m.diskDelta})
+			if errors.Is(err, errFrameDropped) {
+				m.killMsg = err.Error()
+				recordCmd = tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+			} else if err != nil {
+				m.killMsg = fmt.Sprintf("record error: %v", err)
+				m.recorder = nil
+			}
+		}
+
 		// Update selection tracking with new process list
-		m.resolveSelection(m.filteredProcesses())
+		m.pruneCollapsed()
+		m.pruneTagged()
+		m.diag = metrics.Diagnose(m.snap.Processes, m.diag, m.snap.CollectedAt)
+		m.resolveSelection(m.rows())
 
 		// Record sparkline history
 		m.cpuHistory = appendHistory(m.cpuHistory, newSnap.CPU.Total)
@@ -139,7 +230,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.gpuHistory = appendHistory(m.gpuHistory, newSnap.GPU.Utilization)
 		}
 
-		return m, nil
+		alertCmd := m.evaluateAlerts()
+		detailCmd := m.refreshDetail()
+		if m.remote != nil {
+			return m, tea.Batch(waitForRemote(m.remote), alertCmd, recordCmd)
+		}
+		detailsCmd := m.detailsCmd()
+		return m, tea.Batch(alertCmd, detailsCmd, detailCmd, recordCmd)
 
 	case flashDoneMsg:
 		m.refreshFlash = false
@@ -150,10 +247,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case processDetailMsg:
-		if msg.err == nil {
-			m.showDetail = &msg.detail
-		}
-		return m, nil
+		return m.handleProcessDetail(msg)
 	}
 
 	return m, nil
@@ -181,9 +275,47 @@ func (m Model) View() string {
 		return ui.RenderProcessDetail(*m.showDetail, w, h)
 	}
 
+	if m.columnPicker != nil {
+		return ui.RenderColumnPicker(ui.ColumnPickerItems(m.columns), m.columnPicker.cursor, w, h)
+	}
+
+	if m.signalMenu != nil {
+		t := m.signalMenu.target
+		target := t.target()
+		if len(t.procs) == 1 {
+			target += " " + t.procs[0].Name
+		}
+		return ui.RenderSignalMenu(signalChoices(), m.signalMenu.cursor, target, w, h)
+	}
+
+	if m.confirm != nil && len(m.confirm.procs) > 1 {
+		title := m.confirm.String()
+		return ui.RenderConfirmList(strings.ToUpper(title[:1])+title[1:]+"?", m.confirm.confirmItems(), w, h)
+	}
+
+	if m.showAlerts && m.alerts != nil {
+		return ui.RenderAlertsOverlay(m.alerts.Firing(), m.alerts.History(), len(m.alerts.Rules()), w, h)
+	}
+
+	if m.diagView != nil {
+		return ui.RenderDiagnostics(m.diag, clampIndex(m.diagView.cursor, m.diag.Count()), m.killMsg, m.snap.CollectedAt, w, h)
+	}
+
+	if m.eventsView != nil {
+		return ui.RenderProcessEvents(m.eventRows(), len(m.snap.ProcessEvents), m.eventsView.label(), m.eventsView.cursor, m.killMsg, w, h)
+	}
+
 	// Header
 	batteryLabel := ui.RenderBattery(m.snap.Battery)
 	refreshLabel := fmt.Sprintf("  refresh %s", m.cfg.RefreshInterval)
+	if m.replay != nil {
+		refreshLabel = m.replay.label()
+	} else if m.remote != nil {
+		refreshLabel = m.remoteLabel()
+	}
+	if m.recorder != nil {
+		refreshLabel += "  ● rec"
+	}
 	var header string
 	if m.refreshFlash {
 		header = ui.TitleStyle.Render("hideTop") +
@@ -198,10 +330,20 @@ func (m Model) View() string {
 	if stale := m.snap.Status.StaleMetrics(); len(stale) > 0 {
 		header += "  " + lipgloss.NewStyle().Bold(true).Foreground(ui.ColorYellow).Render("stale:"+strings.Join(stale, ","))
 	}
+	if m.alerts != nil {
+		if badge := ui.RenderAlertBadge(len(m.alerts.Firing())); badge != "" {
+			header += "  " + badge
+		}
+	}
+	if m.remoteErr != nil {
+		header += "  " + lipgloss.NewStyle().Bold(true).Foreground(ui.ColorRed).Render(m.remoteErr.Error())
+	}
 	if batteryLabel != "" {
 		header += "  " + batteryLabel
 	}
-	if m.killMsg != "" {
+	if m.prompt != nil {
+		header += "  " + lipgloss.NewStyle().Bold(true).Foreground(ui.ColorYellow).Render(m.prompt.label())
+	} else if m.killMsg != "" {
 		header += "  " + lipgloss.NewStyle().Bold(true).Foreground(ui.ColorRed).Render(m.killMsg)
 	}
 
@@ -221,16 +363,24 @@ func (m Model) View() string {
 
 	// Filter processes and resolve PID-based selection.
 	procs := m.filteredProcesses()
-	selectedIdx, _ := findSelectionIndex(m.selectedPID, procs, m.lastSelectedIdx)
+	selectedIdx, _ := findSelectionIndex(m.selectedPID, m.rows(), m.lastSelectedIdx)
 
 	procState := ui.ProcessViewState{
 		SortBy:      m.sortBy,
+		SortReverse: m.sortReverse,
 		SelectedIdx: selectedIdx,
 		SearchQuery: m.searchQuery,
+		SearchError: m.filterErr,
 		Searching:   m.searching,
 		TreeView:    m.treeView,
-		HideSystem:  m.hideSystem,
+		Collapsed:   m.collapsed,
+		Tagged:      m.taggedPIDs(),
+		HideSystem:  m.hideSystem && !m.byUser(),
 		TotalProcs:  len(m.snap.Processes),
+		GroupBy:     m.groupBy,
+		GroupFilter: m.groupFilter,
+		Columns:     m.columns,
+		Now:         m.snap.CollectedAt,
 	}
 
 	// Count lines used by fixed panels to size the process panel.
@@ -245,7 +395,14 @@ func (m Model) View() string {
 	if procRows < 3 {
 		procRows = 3
 	}
-	procPanel := ui.RenderProcesses(procs, procState, w, procRows)
+	var procPanel string
+	if m.groupBy != metrics.GroupNone {
+		groups := metrics.GroupProcesses(procs, m.groupBy, m.sortBy, m.sortReverse)
+		procState.SelectedIdx = m.groupIndex(groups)
+		procPanel = ui.RenderProcessGroups(groups, procState, w, procRows)
+	} else {
+		procPanel = ui.RenderProcesses(m.withDetails(procs), procState, w, procRows)
+	}
 	helpBar := ui.RenderHelp(w)
 
 	return lipgloss.JoinVertical(lipgloss.Left,
@@ -257,33 +414,74 @@ func (m Model) View() string {
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle kill confirmation first
-	if m.confirmKill != 0 {
+	// Handle action confirmation first
+	if m.confirm != nil {
+		a := *m.confirm
+		m.confirm = nil
+		m.killMsg = ""
 		switch msg.String() {
 		case "y", "Y":
-			m.killMsg = m.killSelectedProcess(m.confirmKill)
-			m.confirmKill = 0
-			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
-		default:
-			m.confirmKill = 0
-			m.killMsg = ""
+			m.killMsg = a.String() + "…"
+			return m, m.runControl(a)
 		}
 		return m, nil
 	}
 
-	// Close detail overlay on Esc or Enter
+	if m.prompt != nil {
+		return m.handlePromptKey(msg)
+	}
+
+	if m.signalMenu != nil {
+		return m.handleSignalMenuKey(msg.String()), nil
+	}
+
 	if m.showDetail != nil {
+		return m.handleDetailKey(msg.String()), nil
+	}
+
+	if m.columnPicker != nil {
+		return m.handleColumnPickerKey(msg.String()), nil
+	}
+
+	// Close alerts overlay on Esc or a
+	if m.showAlerts {
 		switch msg.String() {
-		case "esc", "enter", "q":
-			m.showDetail = nil
+		case "esc", "a", "q":
+			m.showAlerts = false
 		}
 		return m, nil
 	}
 
+	if m.diagView != nil {
+		return m.handleDiagKey(msg.String())
+	}
+
+	if m.eventsView != nil {
+		return m.handleEventsKey(msg.String())
+	}
+
 	if m.searching {
 		return m.handleSearchKey(msg)
 	}
 
+	if m.replay != nil {
+		if rm, cmd, ok := m.handleReplayKey(msg.String()); ok {
+			return rm, cmd
+		}
+	}
+
+	if m.groupBy != metrics.GroupNone {
+		if gm, cmd, ok := m.handleGroupKey(msg.String()); ok {
+			return gm, cmd
+		}
+	}
+
+	if m.treeView {
+		if tm, ok := m.handleTreeKey(msg.String()); ok {
+			return tm, nil
+		}
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
 		m.quitting = true
@@ -292,21 +490,10 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.collectCancel = nil
 		}
 		return m, tea.Quit
-	case "c":
-		if m.sortBy != metrics.SortByCPU {
-			m.sortBy = metrics.SortByCPU
-			m.treeView = false
-		}
-	case "m":
-		if m.sortBy != metrics.SortByMem {
-			m.sortBy = metrics.SortByMem
-			m.treeView = false
-		}
-	case "p":
-		if m.sortBy != metrics.SortByPID {
-			m.sortBy = metrics.SortByPID
-			m.treeView = false
-		}
+	case "c", "m", "p", "i", "u", "n", "T", "S", "o", "r":
+		m.setSort(sortKeys[msg.String()])
+	case "<", ">":
+		m.cycleSort(msg.String() == ">")
 	case "+", "=":
 		m.cfg.RefreshInterval += 250 * time.Millisecond
 		m.refreshFlash = true
@@ -318,7 +505,7 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.refreshFlash = true
 		return m, tea.Tick(300*time.Millisecond, func(time.Time) tea.Msg { return flashDoneMsg{} })
 	case "j", "down":
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx < 0 {
@@ -328,7 +515,7 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case "k", "up":
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx < 0 {
@@ -339,29 +526,56 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "/":
 		m.searching = true
+	case "D":
+		m.diagView = &diagViewState{}
+	case "L":
+		m.eventsView = &eventsViewState{}
+	case "M":
+		m.memExpanded = !m.memExpanded
+	case "F":
+		if len(m.cfg.Filters) == 0 {
+			m.killMsg = "no named filters configured"
+			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+		}
+		m.cycleNamedFilter()
 	case "?":
 		m.showHelp = !m.showHelp
+	case "a":
+		if m.alerts == nil {
+			m.killMsg = "no alert rules configured"
+			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
+		}
+		m.showAlerts = true
 	case "t":
 		m.treeView = !m.treeView
+		m.groupBy = metrics.GroupNone
+	case "C":
+		m.columnPicker = &columnPickerState{}
+	case "g":
+		m.groupBy = m.groupBy.Next()
+		m.selectedGroup = ""
+		m.groupIdx = 0
+		m.groupFilter = ""
+		m.treeView = false
+	case "esc":
+		if m.groupFilter != "" {
+			m.groupBy = m.groupFilterBy
+			m.selectedGroup = m.groupFilter
+			m.groupFilter = ""
+		}
 	case "s":
 		m.hideSystem = !m.hideSystem
-	case "K":
-		if m.selectedPID > 0 {
-			m.confirmKill = signalKill
-			m.killMsg = fmt.Sprintf("SIGKILL PID %d? (y/N)", m.selectedPID)
-		}
-	case "x":
-		if m.selectedPID > 0 {
-			m.confirmKill = signalTerm
-			m.killMsg = fmt.Sprintf("Kill PID %d? (y/N)", m.selectedPID)
-		}
+	case "x", "K", "X", "N", "A":
+		m = m.startControl(msg.String())
+	case " ", "*", "U":
+		m = m.handleTagKey(msg.String())
 	case "e":
 		msg := m.exportSnapshot()
 		m.killMsg = msg // reuse the status area
 		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} })
 	case "enter":
 		if m.selectedPID > 0 {
-			return m, fetchProcessDetail(m.selectedPID, m.snap.Processes)
+			return m.openDetail()
 		}
 	}
 
@@ -369,13 +583,24 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	if m.showHelp || m.showDetail != nil || m.confirmKill != 0 {
+	if m.showHelp || m.showDetail != nil || m.showAlerts || m.columnPicker != nil ||
+		m.confirm != nil || m.signalMenu != nil || m.prompt != nil || m.diagView != nil ||
+		m.eventsView != nil {
+		return m, nil
+	}
+	if m.groupBy != metrics.GroupNone {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m, _, _ = m.handleGroupKey("up")
+		case tea.MouseButtonWheelDown:
+			m, _, _ = m.handleGroupKey("down")
+		}
 		return m, nil
 	}
 
 	switch msg.Button {
 	case tea.MouseButtonWheelUp:
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx <= 0 {
@@ -385,7 +610,7 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case tea.MouseButtonWheelDown:
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx < 0 {
@@ -398,7 +623,7 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		if msg.Action == tea.MouseActionRelease {
 			return m, nil
 		}
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) == 0 {
 			return m, nil
 		}
@@ -410,17 +635,7 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 
 		// Compute viewport start (same logic as RenderProcesses)
 		selectedIdx, _ := findSelectionIndex(m.selectedPID, procs, m.lastSelectedIdx)
-		h := m.height
-		if h == 0 {
-			h = 24
-		}
-		usedLines := m.computeUsedLines()
-		emptyProc := ui.RenderProcesses(nil, ui.ProcessViewState{}, m.width, 0)
-		procOverhead := strings.Count(emptyProc, "\n") + 1
-		maxRows := h - usedLines - procOverhead
-		if maxRows < 3 {
-			maxRows = 3
-		}
+		maxRows := m.procRows()
 		viewStart := 0
 		if maxRows > 0 && selectedIdx >= maxRows {
 			viewStart = selectedIdx - maxRows + 1
@@ -469,16 +684,13 @@ func (m Model) buildMetricsSection(colL, colR int, twoCol bool) string {
 	gpuPanel := ui.RenderGPU(m.snap.GPU, colR, m.gpuHistory)
 	tempPanel := ui.RenderTemperature(m.snap.Temperature, colR)
 	netPanel := ui.RenderNetwork(m.netDelta, colL)
-	diskPanel := ui.RenderDisk(m.diskDelta, m.snap.Disk, colR)
+	diskPanel := ui.RenderDisk(m.diskDelta, m.snap.Disk, m.snap.Processes, colR)
 
-	var memPanel string
-	if twoCol && gpuPanel != "" {
-		memPanel = ui.RenderMemory(m.snap.Memory, m.snap.Load, colL, m.memHistory)
-	} else if twoCol {
-		memPanel = ui.RenderMemory(m.snap.Memory, m.snap.Load, colR, m.memHistory)
-	} else {
-		memPanel = ui.RenderMemory(m.snap.Memory, m.snap.Load, colL, m.memHistory)
+	memW := colL
+	if twoCol && gpuPanel == "" {
+		memW = colR
 	}
+	memPanel := ui.RenderMemory(m.snap.Memory, m.snap.Load, m.snap.Pressure, memW, m.memHistory, m.memExpanded)
 
 	var metricRows []string
 
@@ -542,20 +754,20 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case tea.KeyEscape:
 		m.searching = false
-		m.searchQuery = ""
+		m.setSearchQuery("")
 	case tea.KeyEnter:
 		m.searching = false
 		// After confirming search, open detail if a process is selected
 		if m.selectedPID > 0 {
-			return m, fetchProcessDetail(m.selectedPID, m.snap.Processes)
+			return m.openDetail()
 		}
 	case tea.KeyBackspace:
 		r := []rune(m.searchQuery)
 		if len(r) > 0 {
-			m.searchQuery = string(r[:len(r)-1])
+			m.setSearchQuery(string(r[:len(r)-1]))
 		}
 	case tea.KeyUp:
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx < 0 {
@@ -565,7 +777,7 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case tea.KeyDown:
-		procs := m.filteredProcesses()
+		procs := m.rows()
 		if len(procs) > 0 {
 			idx := m.resolveSelection(procs)
 			if idx < 0 {
@@ -574,8 +786,10 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.selectedPID = procs[idx+1].PID
 			}
 		}
+	case tea.KeySpace:
+		m.setSearchQuery(m.searchQuery + " ")
 	case tea.KeyRunes:
-		m.searchQuery += string(msg.Runes)
+		m.setSearchQuery(m.searchQuery + string(msg.Runes))
 	}
 	return m, nil
 }
@@ -584,7 +798,14 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) filteredProcesses() []metrics.ProcessInfo {
 	procs := m.snap.Processes
 
-	if m.hideSystem {
+	// Replayed and remote snapshots (and live ones until the next process
+	// sample) may be sorted differently from the active sort field.
+	if m.snap.ProcessSortBy != m.sortBy || m.snap.ProcessSortReverse != m.sortReverse {
+		procs = append([]metrics.ProcessInfo(nil), procs...)
+		metrics.SortProcesses(procs, m.sortBy, m.sortReverse)
+	}
+
+	if m.hideSystem && !m.byUser() {
 		hidden := make(map[string]bool, len(m.cfg.FilterUsers))
 		for _, u := range m.cfg.FilterUsers {
 			hidden[u] = true
@@ -598,19 +819,17 @@ func (m Model) filteredProcesses() []metrics.ProcessInfo {
 		procs = filtered
 	}
 
-	if m.searchQuery == "" {
-		return procs
-	}
-	query := strings.ToLower(m.searchQuery)
-	var result []metrics.ProcessInfo
-	for _, p := range procs {
-		if strings.Contains(strings.ToLower(p.Name), query) ||
-			strings.Contains(strings.ToLower(p.User), query) ||
-			strings.Contains(fmt.Sprintf("%d", p.PID), query) {
-			result = append(result, p)
+	if m.groupFilter != "" {
+		var inGroup []metrics.ProcessInfo
+		for _, p := range procs {
+			if metrics.GroupKey(p, m.groupFilterBy) == m.groupFilter {
+				inGroup = append(inGroup, p)
+			}
 		}
+		procs = inGroup
 	}
-	return result
+
+	return m.applyFilter(procs)
 }
 
 // findSelectionIndex resolves selectedPID to an index in the given slice.
@@ -648,23 +867,14 @@ func (m *Model) resolveSelection(procs []metrics.ProcessInfo) int {
 	return idx
 }
 
-func (m Model) collectionTimeout() time.Duration {
-	timeout := m.cfg.RefreshInterval * 2
-	if timeout < time.Second {
-		timeout = time.Second
-	}
-	if timeout > 5*time.Second {
-		timeout = 5 * time.Second
-	}
-	return timeout
-}
-
-func (m Model) processSampleEvery() time.Duration {
-	sampleEvery := 2 * time.Second
-	if m.cfg.RefreshInterval > sampleEvery {
-		return m.cfg.RefreshInterval
+// sampleInterval returns the seconds between the samples of the named
+// collector in two snapshots, or 0 when it was not resampled in between.
+func sampleInterval(cur, prev metrics.Snapshot, name string) float64 {
+	c, p := cur.SampledAt[name], prev.SampledAt[name]
+	if c.IsZero() || p.IsZero() {
+		return 0
 	}
-	return sampleEvery
+	return c.Sub(p).Seconds()
 }
 
 func tick(d time.Duration) tea.Cmd {
@@ -673,9 +883,11 @@ func tick(d time.Duration) tea.Cmd {
 	})
 }
 
-func collectSnapshot(ctx context.Context, sortBy metrics.SortField, previous metrics.Snapshot, processSampleEvery time.Duration, procLimit int, opts metrics.CollectOptions) tea.Cmd {
+// collectSnapshot collects the whole process table without details;
+// they are read for the shown rows only (see detailsCmd).
+func collectSnapshot(ctx context.Context, sortBy metrics.SortField, previous metrics.Snapshot, processSampleEvery time.Duration, opts metrics.CollectOptions) tea.Cmd {
 	return func() tea.Msg {
-		snap := metrics.Collect(ctx, 200*time.Millisecond, sortBy, procLimit, processSampleEvery, previous, opts)
+		snap := metrics.Collect(ctx, sortBy, 0, processSampleEvery, previous, opts)
 		return snapshotMsg(snap)
 	}
 }
@@ -703,17 +915,6 @@ func appendHistory(h []float64, v float64) []float64 {
 	return h
 }
 
-func (m Model) killSelectedProcess(sig killSignal) string {
-	if m.selectedPID <= 0 {
-		return ""
-	}
-	err := killProcess(int(m.selectedPID), sig)
-	if err != nil {
-		return fmt.Sprintf("kill %d: %v", m.selectedPID, err)
-	}
-	return fmt.Sprintf("sent signal %d to PID %d", sig, m.selectedPID)
-}
-
 func (m Model) exportSnapshot() string {
 	basename := fmt.Sprintf("hideTop_%s.json", m.snap.CollectedAt.Format("20060102_150405"))
 	// Write to user's home directory for a predictable location.
@@ -731,42 +932,3 @@ func (m Model) exportSnapshot() string {
 	}
 	return fmt.Sprintf("exported to %s", filename)
 }
-
-func fetchProcessDetail(pid int32, procs []metrics.ProcessInfo) tea.Cmd {
-	return func() tea.Msg {
-		// Find base info from snapshot
-		var base metrics.ProcessInfo
-		for _, p := range procs {
-			if p.PID == pid {
-				base = p
-				break
-			}
-		}
-
-		detail := ui.ProcessDetail{ProcessInfo: base}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		proc, err := process.NewProcessWithContext(ctx, pid)
-		if err != nil {
-			return processDetailMsg{detail: detail, err: nil}
-		}
-
-		if cmd, err := proc.CmdlineWithContext(ctx); err == nil {
-			detail.Cmdline = cmd
-		}
-		if fds, err := proc.NumFDsWithContext(ctx); err == nil {
-			detail.NumFDs = fds
-		}
-		if mem, err := proc.MemoryInfoWithContext(ctx); err == nil && mem != nil {
-			detail.RSS = mem.RSS
-			detail.VMS = mem.VMS
-		}
-		if ct, err := proc.CreateTimeWithContext(ctx); err == nil {
-			detail.CreateTime = ct
-		}
-
-		return processDetailMsg{detail: detail}
-	}
-}
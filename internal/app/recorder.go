@@ -0,0 +1,79 @@
+package app
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/youhide/hideTop/internal/record"
+)
+
+// recorderQueue is how many frames may wait for the disk before new ones
+// are dropped.
+const recorderQueue = 32
+
+// errFrameDropped is returned by recorder.write when the queue is full.
+var errFrameDropped = errors.New("record: disk too slow, frame dropped")
+
+// recorder appends frames to a recording from its own goroutine, so gob
+// encoding, compression and disk writes stay out of Update. The pointer
+// is shared by every copy of the Model.
+type recorder struct {
+	w      *record.Writer
+	frames chan record.Frame
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error // first write error; later frames are discarded
+}
+
+func newRecorder(w *record.Writer) *recorder {
+	r := &recorder{w: w, frames: make(chan record.Frame, recorderQueue), done: make(chan struct{})}
+	go r.run()
+	return r
+}
+
+func (r *recorder) run() {
+	defer close(r.done)
+	for fr := range r.frames {
+		if r.failed() != nil {
+			continue
+		}
+		if err := r.w.Write(fr); err != nil {
+			r.mu.Lock()
+			r.err = err
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *recorder) failed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// write queues fr without waiting for the disk. It returns the error of
+// an earlier write, after which recording has stopped, or
+// errFrameDropped if the queue is full.
+func (r *recorder) write(fr record.Frame) error {
+	if err := r.failed(); err != nil {
+		return err
+	}
+	select {
+	case r.frames <- fr:
+		return nil
+	default:
+		return errFrameDropped
+	}
+}
+
+// close writes the queued frames and closes the recording.
+func (r *recorder) close() error {
+	close(r.frames)
+	<-r.done
+	cerr := r.w.Close()
+	if err := r.failed(); err != nil {
+		return err
+	}
+	return cerr
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/remote"
+)
+
+// remoteClosedMsg reports that the agent connection ended.
+type remoteClosedMsg struct{ err error }
+
+// SetRemote makes the model render snapshots streamed by an agent
+// instead of collecting locally.
+func (m *Model) SetRemote(c *remote.Client) {
+	m.remote = c
+}
+
+// waitForRemote blocks until the agent sends the next snapshot.
+func waitForRemote(c *remote.Client) tea.Cmd {
+	return func() tea.Msg {
+		snap, ok := <-c.Snapshots()
+		if !ok {
+			return remoteClosedMsg{err: c.Err()}
+		}
+		return snapshotMsg(snap)
+	}
+}
+
+// remoteLabel renders the connected agent for the header.
+func (m Model) remoteLabel() string {
+	host := m.remote.Hostname()
+	if host == "" {
+		host = m.remote.Addr()
+	}
+	return fmt.Sprintf("  remote %s", host)
+}
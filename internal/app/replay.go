@@ -0,0 +1,203 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/record"
+)
+
+// Replay speed bounds; speed doubles/halves with ] and [.
+const (
+	minReplaySpeed = 0.25
+	maxReplaySpeed = 64
+)
+
+// replayTickMsg advances playback. gen ties it to the playback run that
+// scheduled it so stale ticks are dropped after pause, step or speed
+// changes.
+type replayTickMsg struct{ gen int }
+
+// replayState drives the model from a recording instead of live collection.
+type replayState struct {
+	rec    *record.Recording
+	pos    int
+	paused bool
+	speed  float64
+	gen    int
+
+	// window holds the decoded frames from windowStart up to pos, at most
+	// historySize of them, for sparkline history and diagnostics. The
+	// rest of the recording stays on disk.
+	window      []record.Frame
+	windowStart int
+}
+
+// SetReplay switches the model to replay rec.
+func (m *Model) SetReplay(rec *record.Recording) error {
+	m.replay = &replayState{rec: rec, speed: 1}
+	return m.showFrame(0)
+}
+
+// SetRecorder makes the model append every live snapshot to w.
+func (m *Model) SetRecorder(w *record.Writer) {
+	m.recorder = newRecorder(w)
+}
+
+// StopRecording writes out the frames still queued and closes the
+// recording, if there is one.
+func (m Model) StopRecording() error {
+	if m.recorder == nil {
+		return nil
+	}
+	return m.recorder.close()
+}
+
+// showFrame displays frame i and rebuilds sparkline history from the
+// frames leading up to it, so stepping backwards shows the same history
+// as playing forwards.
+func (m *Model) showFrame(i int) error {
+	r := m.replay
+	i = min(max(i, 0), r.rec.Len()-1)
+	step := i == r.pos+1 && len(r.window) > 0
+
+	window, start, err := r.load(i)
+	if err != nil {
+		return err
+	}
+	r.window, r.windowStart, r.pos = window, start, i
+
+	fr := window[len(window)-1]
+	if len(window) > 1 {
+		m.prevSnap = window[len(window)-2].Snapshot
+	}
+	m.snap = fr.Snapshot
+	m.netDelta = fr.NetDelta
+	m.diskDelta = fr.DiskDelta
+
+	m.cpuHistory, m.memHistory, m.gpuHistory = nil, nil, nil
+	for _, w := range window {
+		s := w.Snapshot
+		m.cpuHistory = appendHistory(m.cpuHistory, s.CPU.Total)
+		m.memHistory = appendHistory(m.memHistory, s.Memory.Percent)
+		if s.GPU != nil && s.GPU.Available {
+			m.gpuHistory = appendHistory(m.gpuHistory, s.GPU.Utilization)
+		}
+	}
+
+	// Diagnostics durations need the frames before this one: replay the
+	// history window after a seek, or just this frame when playing on.
+	if step {
+		m.diag = metrics.Diagnose(m.snap.Processes, m.diag, m.snap.CollectedAt)
+	} else {
+		m.diag = metrics.Diagnostics{}
+		for _, w := range window {
+			m.diag = metrics.Diagnose(w.Snapshot.Processes, m.diag, w.Snapshot.CollectedAt)
+		}
+	}
+
+	m.resolveSelection(m.rows())
+	m.refreshDetail() // replayed processes are never inspected, so no command
+	return nil
+}
+
+// load returns the window of frames ending at i, reusing the frames of
+// the current window and reading only the others from the recording.
+func (r *replayState) load(i int) ([]record.Frame, int, error) {
+	start := max(0, i-historySize+1)
+	window := make([]record.Frame, 0, i-start+1)
+	for j := start; j <= i; j++ {
+		if k := j - r.windowStart; k >= 0 && k < len(r.window) {
+			window = append(window, r.window[k])
+			continue
+		}
+		fr, err := r.rec.Frame(j)
+		if err != nil {
+			return nil, 0, err
+		}
+		window = append(window, fr)
+	}
+	return window, start, nil
+}
+
+// seek shows frame i, pausing playback if it cannot be read.
+func (m *Model) seek(i int) {
+	if err := m.showFrame(i); err != nil {
+		m.replay.paused = true
+		m.killMsg = fmt.Sprintf("replay error: %v", err)
+	}
+}
+
+// replayTick schedules the next frame after the recorded gap between the
+// current and next frame, scaled by the playback speed.
+func (m Model) replayTick() tea.Cmd {
+	r := m.replay
+	if r.paused || r.pos >= r.rec.Len()-1 {
+		return nil
+	}
+	gap := r.rec.Time(r.pos + 1).Sub(r.rec.Time(r.pos))
+	if gap <= 0 || gap > time.Minute {
+		gap = m.cfg.RefreshInterval
+	}
+	gap = time.Duration(float64(gap) / r.speed)
+	gen := r.gen
+	return tea.Tick(gap, func(time.Time) tea.Msg { return replayTickMsg{gen: gen} })
+}
+
+func (m Model) handleReplayTick(msg replayTickMsg) (tea.Model, tea.Cmd) {
+	r := m.replay
+	if msg.gen != r.gen || r.paused {
+		return m, nil
+	}
+	m.seek(r.pos + 1)
+	if r.paused || r.pos >= r.rec.Len()-1 {
+		r.paused = true
+		return m, nil
+	}
+	return m, m.replayTick()
+}
+
+// handleReplayKey handles playback keys. It reports false for keys that
+// should fall through to the normal key handling.
+func (m Model) handleReplayKey(key string) (Model, tea.Cmd, bool) {
+	r := m.replay
+	switch key {
+	case " ":
+		r.paused = !r.paused
+		if !r.paused && r.pos >= r.rec.Len()-1 {
+			m.seek(0) // restart from the beginning
+		}
+	case ".":
+		r.paused = true
+		m.seek(r.pos + 1)
+	case ",":
+		r.paused = true
+		m.seek(r.pos - 1)
+	case "]":
+		r.speed = min(r.speed*2, maxReplaySpeed)
+	case "[":
+		r.speed = max(r.speed/2, minReplaySpeed)
+	case "x", "K", "X", "N", "A":
+		m.killMsg = "not available in replay"
+		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return killMsgClearMsg{} }), true
+	case "+", "=", "-", "_":
+		return m, nil, true
+	default:
+		return m, nil, false
+	}
+	r.gen++
+	return m, m.replayTick(), true
+}
+
+// replayLabel renders the playback position for the header.
+func (r *replayState) label() string {
+	s := fmt.Sprintf("  replay %d/%d %s ×%g", r.pos+1, r.rec.Len(),
+		r.rec.Time(r.pos).Format("2006-01-02 15:04:05"), r.speed)
+	if r.paused {
+		s += " [paused]"
+	}
+	return s
+}
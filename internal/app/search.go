@@ -0,0 +1,64 @@
+package app
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/youhide/hideTop/internal/filter"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// setSearchQuery sets the search prompt text and parses it as a filter.
+// While the text does not parse, the last valid filter stays applied and
+// the error is shown next to the prompt.
+func (m *Model) setSearchQuery(q string) {
+	m.searchQuery = q
+	f, err := filter.Parse(q, m.cfg.Filters)
+	if err != nil {
+		m.filterErr = err.Error()
+		return
+	}
+	m.filterErr = ""
+	m.filter = f
+	if strings.TrimSpace(q) == "" {
+		m.filter = nil
+	}
+}
+
+// cycleNamedFilter applies the next named filter from the config file,
+// then none after the last.
+func (m *Model) cycleNamedFilter() {
+	names := make([]string, 0, len(m.cfg.Filters))
+	for name := range m.cfg.Filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	next := 0
+	if i := slices.Index(names, strings.TrimPrefix(m.searchQuery, "@")); i >= 0 && strings.HasPrefix(m.searchQuery, "@") {
+		next = i + 1
+	}
+	if next == len(names) {
+		m.setSearchQuery("")
+		return
+	}
+	m.setSearchQuery("@" + names[next])
+}
+
+// applyFilter returns the processes matching the search filter. Filters
+// on detail fields see the details read so far.
+func (m Model) applyFilter(procs []metrics.ProcessInfo) []metrics.ProcessInfo {
+	if m.filter == nil {
+		return procs
+	}
+	candidates := procs
+	if m.filter.NeedsDetails() {
+		candidates = m.withDetails(procs)
+	}
+	var result []metrics.ProcessInfo
+	for i, p := range candidates {
+		if m.filter.Match(p) {
+			result = append(result, procs[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// sortKeys maps sort keys to fields. Pressing the key of the active
+// field flips its direction.
+var sortKeys = map[string]metrics.SortField{
+	"c": metrics.SortByCPU,
+	"m": metrics.SortByMem,
+	"p": metrics.SortByPID,
+	"i": metrics.SortByIO,
+	"u": metrics.SortByUser,
+	"n": metrics.SortByName,
+	"T": metrics.SortByThreads,
+	"S": metrics.SortByState,
+	"o": metrics.SortByStart,
+	"r": metrics.SortByRSS,
+}
+
+// setSort sorts by f in its default direction, or flips the direction
+// if f is already active. In tree view it orders each parent's children.
+func (m *Model) setSort(f metrics.SortField) {
+	if m.sortBy == f {
+		m.sortReverse = !m.sortReverse
+	} else {
+		m.sortBy = f
+		m.sortReverse = false
+	}
+}
+
+// cycleSort moves to the next (or previous) sortable column in the
+// current column order.
+func (m *Model) cycleSort(next bool) {
+	fields := ui.SortFields(m.columns)
+	if len(fields) == 0 {
+		return
+	}
+	i := slices.Index(fields, m.sortBy)
+	switch {
+	case i < 0:
+		i = 0
+	case next:
+		i = (i + 1) % len(fields)
+	default:
+		i = (i - 1 + len(fields)) % len(fields)
+	}
+	m.sortBy = fields[i]
+	m.sortReverse = false
+}
+
+// collectOptions returns the collection options for the current sort.
+func (m Model) collectOptions() metrics.CollectOptions {
+	opts := m.cfg.CollectOptions()
+	opts.SortReverse = m.sortReverse
+	return opts
+}
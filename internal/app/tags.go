@@ -0,0 +1,83 @@
+package app
+
+import (
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// isTagged reports whether p is tagged. Tags remember the create time,
+// so a reused PID is not acted on by mistake.
+func (m Model) isTagged(p metrics.ProcessInfo) bool {
+	created, ok := m.tagged[p.PID]
+	return ok && created == p.CreateTime
+}
+
+// taggedPIDs returns the tagged PIDs for rendering.
+func (m Model) taggedPIDs() map[int32]bool {
+	if len(m.tagged) == 0 {
+		return nil
+	}
+	pids := make(map[int32]bool, len(m.tagged))
+	for pid := range m.tagged {
+		pids[pid] = true
+	}
+	return pids
+}
+
+// handleTagKey tags processes for batch actions: space toggles the
+// selected process and moves down, * tags every shown row (such as all
+// search matches) and U clears the tags.
+func (m Model) handleTagKey(key string) Model {
+	switch key {
+	case " ":
+		procs := m.rows()
+		idx := m.resolveSelection(procs)
+		if idx < 0 {
+			return m
+		}
+		m.tagged = cloneTags(m.tagged)
+		if p := procs[idx]; m.isTagged(p) {
+			delete(m.tagged, p.PID)
+		} else {
+			m.tagged[p.PID] = p.CreateTime
+		}
+		if idx+1 < len(procs) {
+			m.selectedPID = procs[idx+1].PID
+		}
+	case "*":
+		m.tagged = cloneTags(m.tagged)
+		for _, p := range m.rows() {
+			m.tagged[p.PID] = p.CreateTime
+		}
+	case "U":
+		m.tagged = nil
+	}
+	return m
+}
+
+// cloneTags copies tags before a change, so earlier Model values keep
+// their own set.
+func cloneTags(tags map[int32]int64) map[int32]int64 {
+	out := make(map[int32]int64, len(tags)+1)
+	for pid, created := range tags {
+		out[pid] = created
+	}
+	return out
+}
+
+// pruneTagged forgets tagged processes that exited.
+func (m *Model) pruneTagged() {
+	if len(m.tagged) == 0 {
+		return
+	}
+	alive := make(map[int32]int64, len(m.snap.Processes))
+	for _, p := range m.snap.Processes {
+		alive[p.PID] = p.CreateTime
+	}
+	tags := make(map[int32]int64, len(m.tagged))
+	for pid, created := range m.tagged {
+		if c, ok := alive[pid]; ok && c == created {
+			tags[pid] = created
+		}
+	}
+	m.tagged = tags
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/ui"
+)
+
+// rows returns the process rows in display order: the filtered list, or
+// in tree view its tree order without the collapsed subtrees.
+func (m Model) rows() []metrics.ProcessInfo {
+	procs := m.filteredProcesses()
+	if m.treeView {
+		return ui.TreeOrder(procs, m.collapsed)
+	}
+	return procs
+}
+
+// handleTreeKey collapses and expands the selected process's subtree in
+// tree view. Left on a process that is already collapsed, or has no
+// children, moves to its parent instead.
+func (m Model) handleTreeKey(key string) (Model, bool) {
+	switch key {
+	case "left", "h", "right", "l":
+	default:
+		return m, false
+	}
+	if m.selectedPID <= 0 {
+		return m, true
+	}
+	procs := m.filteredProcesses()
+	var sel metrics.ProcessInfo
+	hasChildren := false
+	for _, p := range procs {
+		if p.PID == m.selectedPID {
+			sel = p
+		}
+		if p.PPID == m.selectedPID && p.PID != m.selectedPID {
+			hasChildren = true
+		}
+	}
+	if sel.PID == 0 {
+		return m, true
+	}
+
+	expand := key == "right" || key == "l"
+	switch {
+	case expand:
+		if m.collapsed[sel.PID] {
+			m.collapsed = cloneCollapsed(m.collapsed)
+			delete(m.collapsed, sel.PID)
+		}
+	case hasChildren && !m.collapsed[sel.PID]:
+		m.collapsed = cloneCollapsed(m.collapsed)
+		m.collapsed[sel.PID] = true
+	default:
+		for _, p := range procs {
+			if p.PID == sel.PPID {
+				m.selectedPID = p.PID
+				break
+			}
+		}
+	}
+	return m, true
+}
+
+// pruneCollapsed forgets collapsed PIDs that exited, so a reused PID
+// does not start out collapsed.
+func (m *Model) pruneCollapsed() {
+	if len(m.collapsed) == 0 {
+		return
+	}
+	alive := make(map[int32]bool, len(m.snap.Processes))
+	for _, p := range m.snap.Processes {
+		alive[p.PID] = true
+	}
+	collapsed := make(map[int32]bool, len(m.collapsed))
+	for pid := range m.collapsed {
+		if alive[pid] {
+			collapsed[pid] = true
+		}
+	}
+	m.collapsed = collapsed
+}
+
+// cloneCollapsed copies collapsed before a change, so earlier Model
+// values and the process view state keep their own set.
+func cloneCollapsed(collapsed map[int32]bool) map[int32]bool {
+	out := make(map[int32]bool, len(collapsed)+1)
+	for pid := range collapsed {
+		out[pid] = true
+	}
+	return out
+}
@@ -5,7 +5,10 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
 )
 
 type Config struct {
@@ -16,7 +19,38 @@ type Config struct {
 	NoGPU           bool
 	NoTemp          bool
 	FilterUsers     []string
-	ProcLimit       int
+	ProcLimit       int      // top processes exported by --serve or sent with details by an agent
+	Serve           string   // listen address for the Prometheus exporter
+	Record          string   // path to record the session to
+	Replay          string   // path of a recording to replay
+	Columns         []string // process table column IDs; empty = default set
+
+	// Remote monitoring
+	Agent   bool   // running as `hideTop agent`
+	Listen  string // agent listen address
+	Connect string // agent address to render instead of collecting locally
+	Token   string // shared secret between agent and client
+
+	// CollectorIntervals overrides per-collector sampling intervals,
+	// keyed by collector name (cpu, mem, load, proc, gpu, temp, net,
+	// disk, bat, psi).
+	CollectorIntervals map[string]time.Duration
+
+	// Alerts are threshold rules from the config file, evaluated by the
+	// TUI on every snapshot.
+	Alerts []AlertRule
+
+	// Filters are named process filters from the config file, used as
+	// @name in the search prompt and cycled with F.
+	Filters map[string]string
+}
+
+// AlertRule is an alert entry in the config file.
+type AlertRule struct {
+	Name    string `json:"name"`    // optional display name
+	Rule    string `json:"rule"`    // e.g. "cpu.total > 90 for 30s"
+	Command string `json:"command"` // shell command run with the alert JSON on stdin
+	Log     string `json:"log"`     // file the alert JSON is appended to
 }
 
 // DefaultFilterUsers is used when no custom filter is configured.
@@ -31,28 +65,68 @@ type fileConfig struct {
 	Debug       bool     `json:"debug"`
 	FilterUsers []string `json:"filter_users"`
 	ProcLimit   int      `json:"proc_limit"`
+	Columns     []string `json:"columns"`
+
+	CollectorIntervals map[string]string `json:"collector_intervals"`
+	Alerts             []AlertRule       `json:"alerts"`
+	Filters            map[string]string `json:"filters"`
 }
 
+// Parse reads CLI flags and the config file. A leading "agent" argument
+// selects the remote agent subcommand, which has its own flag set.
 func Parse() Config {
-	interval := flag.Duration("interval", 1*time.Second,
+	args := os.Args[1:]
+	fs := flag.CommandLine
+	agent := len(args) > 0 && args[0] == "agent"
+	if agent {
+		args = args[1:]
+		fs = flag.NewFlagSet("hideTop agent", flag.ExitOnError)
+	}
+
+	interval := fs.Duration("interval", 1*time.Second,
 		"metrics refresh interval (e.g. 500ms, 1s, 2s)")
-	showVersion := flag.Bool("version", false, "print version and exit")
-	showVersionShort := flag.Bool("v", false, "print version and exit")
-	debug := flag.Bool("debug", false, "enable debug logging to stderr")
-	theme := flag.String("theme", "", "color theme (dark, light, dracula, nord, monokai)")
-	noGPU := flag.Bool("no-gpu", false, "disable GPU metrics")
-	noTemp := flag.Bool("no-temp", false, "disable temperature metrics")
-	procLimit := flag.Int("proc-limit", 0, "max number of processes to display (0 = 50)")
-	flag.Parse()
+	showVersion := fs.Bool("version", false, "print version and exit")
+	showVersionShort := fs.Bool("v", false, "print version and exit")
+	debug := fs.Bool("debug", false, "enable debug logging to stderr")
+	noGPU := fs.Bool("no-gpu", false, "disable GPU metrics")
+	noTemp := fs.Bool("no-temp", false, "disable temperature metrics")
+	procLimit := fs.Int("proc-limit", 0, "number of top processes exported by --serve or sent with details by an agent (0 = 50)")
+	token := fs.String("token", "", "shared secret for agent connections (default $HIDETOP_TOKEN)")
+
+	var theme, serve, recordPath, replayPath, connect, listen, columns string
+	if agent {
+		fs.StringVar(&listen, "listen", ":7777", "address to listen on for TUI clients")
+	} else {
+		fs.StringVar(&theme, "theme", "", "color theme (dark, light, dracula, nord, monokai)")
+		fs.StringVar(&serve, "serve", "", "run headless and serve Prometheus metrics on this address (e.g. :9100)")
+		fs.StringVar(&recordPath, "record", "", "record every snapshot to this file (e.g. session.htrec)")
+		fs.StringVar(&replayPath, "replay", "", "replay a recording made with --record instead of collecting live")
+		fs.StringVar(&connect, "connect", "", "render metrics streamed by a hideTop agent at host:port")
+		fs.StringVar(&columns, "columns", "", "comma-separated process table columns (e.g. pid,user,name,rss,cpu,cmd)")
+	}
+	_ = fs.Parse(args) // ExitOnError: exits on failure
 
 	cfg := Config{
 		RefreshInterval: *interval,
 		ShowVersion:     *showVersion || *showVersionShort,
 		Debug:           *debug,
-		Theme:           *theme,
+		Theme:           theme,
 		NoGPU:           *noGPU,
 		NoTemp:          *noTemp,
 		ProcLimit:       *procLimit,
+		Serve:           serve,
+		Record:          recordPath,
+		Replay:          replayPath,
+		Agent:           agent,
+		Listen:          listen,
+		Connect:         connect,
+		Token:           *token,
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("HIDETOP_TOKEN")
+	}
+	if columns != "" {
+		cfg.Columns = strings.Split(columns, ",")
 	}
 
 	// Load config file (flags take precedence)
@@ -70,6 +144,9 @@ func Parse() Config {
 		if !cfg.NoTemp && fc.NoTemp {
 			cfg.NoTemp = true
 		}
+		if len(cfg.Columns) == 0 {
+			cfg.Columns = fc.Columns
+		}
 		if fc.Interval != "" && *interval == 1*time.Second {
 			if d, err := time.ParseDuration(fc.Interval); err == nil {
 				cfg.RefreshInterval = d
@@ -97,9 +174,60 @@ func Parse() Config {
 		cfg.ProcLimit = 50
 	}
 
+	// Apply collector_intervals from config file, skipping invalid entries
+	if fc != nil {
+		for name, v := range fc.CollectorIntervals {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				continue
+			}
+			if cfg.CollectorIntervals == nil {
+				cfg.CollectorIntervals = make(map[string]time.Duration)
+			}
+			cfg.CollectorIntervals[name] = d
+		}
+	}
+
+	if fc != nil {
+		cfg.Alerts = fc.Alerts
+		cfg.Filters = fc.Filters
+	}
+
 	return cfg
 }
 
+// CollectOptions returns the metrics.CollectOptions implied by the config.
+func (c Config) CollectOptions() metrics.CollectOptions {
+	return metrics.CollectOptions{
+		SkipGPU:   c.NoGPU,
+		SkipTemp:  c.NoTemp,
+		Intervals: c.CollectorIntervals,
+	}
+}
+
+// CollectionTimeout bounds a single metrics.Collect call: twice the
+// refresh interval, clamped to [1s, 5s].
+func (c Config) CollectionTimeout() time.Duration {
+	timeout := c.RefreshInterval * 2
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	if timeout > 5*time.Second {
+		timeout = 5 * time.Second
+	}
+	return timeout
+}
+
+// ProcessSampleEvery is the process collector cadence: every 2s, or the
+// refresh interval if that is longer.
+func (c Config) ProcessSampleEvery() time.Duration {
+	sampleEvery := 2 * time.Second
+	if c.RefreshInterval > sampleEvery {
+		return c.RefreshInterval
+	}
+	return sampleEvery
+}
+
 func loadConfigFile() *fileConfig {
 	home, err := os.UserHomeDir()
 	if err != nil {
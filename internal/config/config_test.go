@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Parse defines its flags on flag.CommandLine, so it can only run once
+// per test binary.
+func TestParse_ConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	dir := filepath.Join(home, ".config", "hideTop")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `{
+		"alerts": [{"name": "busy", "rule": "cpu.total > 1", "log": "alerts.log"}],
+		"filters": {"db": "user:postgres"},
+		"collector_intervals": {"cpu": "2s"}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = []string{"hideTop"}
+
+	cfg := Parse()
+	if len(cfg.Alerts) != 1 || cfg.Alerts[0] != (AlertRule{Name: "busy", Rule: "cpu.total > 1", Log: "alerts.log"}) {
+		t.Errorf("unexpected alerts %+v", cfg.Alerts)
+	}
+	if cfg.Filters["db"] != "user:postgres" {
+		t.Errorf("unexpected filters %+v", cfg.Filters)
+	}
+	if cfg.CollectorIntervals["cpu"] != 2*time.Second {
+		t.Errorf("unexpected collector intervals %+v", cfg.CollectorIntervals)
+	}
+}
@@ -0,0 +1,74 @@
+// Package cpulist reads and writes CPU lists in the kernel's format, as
+// in /sys/devices/system/cpu/online and taskset -c.
+package cpulist
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxCPUs bounds the CPU numbers Parse accepts: the largest NR_CPUS the
+// kernel can be built with.
+const MaxCPUs = 8192
+
+// Parse parses a CPU list such as "0-3,6" into sorted, distinct CPU
+// numbers. CPUs from MaxCPUs up are rejected, so a typo like
+// "0-999999999" cannot expand into a huge list.
+func Parse(s string) ([]int, error) {
+	var set [MaxCPUs]bool
+	found := false
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("bad CPU %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil || last < first {
+				return nil, fmt.Errorf("bad CPU range %q", part)
+			}
+		}
+		if last >= MaxCPUs {
+			return nil, fmt.Errorf("CPU %d out of range (max %d)", last, MaxCPUs-1)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			set[cpu] = true
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("empty CPU list")
+	}
+	var cpus []int
+	for cpu, ok := range set {
+		if ok {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// Format formats sorted CPU numbers as a CPU list, collapsing runs
+// into ranges: [0 1 2 3 6] becomes "0-3,6".
+func Format(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
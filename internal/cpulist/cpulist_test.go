@@ -0,0 +1,38 @@
+package cpulist
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"0", []int{0}},
+		{"0-3,6", []int{0, 1, 2, 3, 6}},
+		{" 6, 2-3 ,3", []int{2, 3, 6}},
+		{"8190-8191", []int{8190, 8191}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "x", "3-1", "-1", "1-", "8192", "0-999999999"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format([]int{0, 1, 2, 3, 6, 8, 9}); got != "0-3,6,8-9" {
+		t.Errorf("Format = %q", got)
+	}
+	if got := Format(nil); got != "" {
+		t.Errorf("Format(nil) = %q", got)
+	}
+}
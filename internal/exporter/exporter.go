@@ -0,0 +1,126 @@
+// Package exporter runs hideTop headless and serves its metrics in the
+// Prometheus text exposition format.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// Exporter collects snapshots on the configured interval and serves the
+// most recent one over HTTP.
+type Exporter struct {
+	cfg config.Config
+
+	mu   sync.RWMutex
+	snap metrics.Snapshot
+}
+
+// New returns an Exporter for cfg. Call Run to start collecting.
+func New(cfg config.Config) *Exporter {
+	return &Exporter{cfg: cfg}
+}
+
+// Serve collects metrics and serves them on cfg.Serve until ctx is done.
+func Serve(ctx context.Context, cfg config.Config) error {
+	e := New(cfg)
+
+	ln, err := net.Listen("tcp", cfg.Serve)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go e.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("serving metrics", "addr", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Run collects a snapshot right after priming the CPU counters and then
+// on every refresh interval until ctx is done.
+func (e *Exporter) Run(ctx context.Context) {
+	e.mu.Lock()
+	e.snap = metrics.PrimeCPU(ctx)
+	e.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(metrics.CPUPrimeWindow):
+	}
+
+	ticker := time.NewTicker(e.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		e.collect(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) collect(ctx context.Context) {
+	e.mu.RLock()
+	previous := e.snap
+	e.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.CollectionTimeout())
+	defer cancel()
+
+	snap := metrics.Collect(ctx, metrics.SortByCPU, 0,
+		e.cfg.ProcessSampleEvery(), previous, e.cfg.CollectOptions())
+	if stale := snap.Status.StaleMetrics(); len(stale) > 0 {
+		slog.Debug("stale metrics", "collectors", stale)
+	}
+
+	e.mu.Lock()
+	e.snap = snap
+	e.mu.Unlock()
+}
+
+// ServeHTTP writes the latest snapshot in Prometheus text format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	snap := e.snap
+	e.mu.RUnlock()
+
+	if snap.CollectedAt.IsZero() {
+		http.Error(w, "no snapshot collected yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, snap, e.cfg.ProcLimit); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
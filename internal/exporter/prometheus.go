@@ -0,0 +1,377 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/metrics/gpu"
+)
+
+const gib = 1 << 30
+
+// WritePrometheus writes snap in the Prometheus text exposition format.
+// Cumulative I/O counters are exported as counters so rates can be taken
+// with rate(); everything else is a gauge. topN limits how many processes
+// are exported (0 = all processes in the snapshot).
+func WritePrometheus(w io.Writer, snap metrics.Snapshot, topN int) error {
+	bw := bufio.NewWriter(w)
+	p := &promWriter{w: bw}
+
+	p.family("hidetop_snapshot_timestamp_seconds", "Time the snapshot was collected.", "gauge")
+	p.sample("hidetop_snapshot_timestamp_seconds", nil, float64(snap.CollectedAt.UnixNano())/1e9)
+
+	writeStatus(p, snap)
+	writeCPU(p, snap.CPU)
+	writeMemory(p, snap.Memory, snap.Load)
+	writePressure(p, snap.Pressure)
+	writeNetwork(p, snap.Network)
+	writeDisk(p, snap.Disk)
+	writeTemperature(p, snap.Temperature)
+	writeGPU(p, snap.GPU)
+	writeBattery(p, snap.Battery)
+	writeProcesses(p, snap.Processes, topN)
+	writeExtra(p, snap.Extra)
+
+	if p.err != nil {
+		return p.err
+	}
+	return bw.Flush()
+}
+
+func writeStatus(p *promWriter, snap metrics.Snapshot) {
+	names := make([]string, 0, len(snap.SampledAt))
+	for name := range snap.SampledAt {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.family("hidetop_collector_stale", "Whether the collector's last run failed and previous values are shown (1 = stale).", "gauge")
+	for _, name := range names {
+		p.sample("hidetop_collector_stale", labels{"collector", name}, boolValue(snap.Status[name].Stale))
+	}
+	p.family("hidetop_collector_sample_timestamp_seconds", "Time the collector's values were last sampled.", "gauge")
+	for _, name := range names {
+		if t := snap.SampledAt[name]; !t.IsZero() {
+			p.sample("hidetop_collector_sample_timestamp_seconds", labels{"collector", name}, float64(t.UnixNano())/1e9)
+		}
+	}
+}
+
+func writeCPU(p *promWriter, cpu metrics.CPUStats) {
+	if len(cpu.PerCore) == 0 {
+		return
+	}
+	p.family("hidetop_cpu_usage_percent", "CPU utilisation across all cores.", "gauge")
+	p.sample("hidetop_cpu_usage_percent", nil, cpu.Total)
+	p.family("hidetop_cpu_core_usage_percent", "Per-core CPU utilisation.", "gauge")
+	for i, v := range cpu.PerCore {
+		p.sample("hidetop_cpu_core_usage_percent", labels{"core", strconv.Itoa(i)}, v)
+	}
+	if len(cpu.Cores) > 0 && cpu.Cores[0].MHz > 0 {
+		p.family("hidetop_cpu_core_frequency_hertz", "Current clock of each logical CPU.", "gauge")
+		for _, c := range cpu.Cores {
+			p.sample("hidetop_cpu_core_frequency_hertz", labels{"core", strconv.Itoa(c.CPU)}, c.MHz*1e6)
+		}
+	}
+	if len(cpu.PerCoreTimes) == 0 {
+		return
+	}
+	t := cpu.Times
+	p.family("hidetop_cpu_mode_percent", "Share of CPU time across all cores by CPU state.", "gauge")
+	for _, m := range []struct {
+		mode string
+		v    float64
+	}{
+		{"user", t.User}, {"nice", t.Nice}, {"system", t.System}, {"idle", t.Idle}, {"iowait", t.IOWait},
+		{"irq", t.IRQ}, {"softirq", t.SoftIRQ}, {"steal", t.Steal}, {"guest", t.Guest},
+	} {
+		p.sample("hidetop_cpu_mode_percent", labels{"mode", m.mode}, m.v)
+	}
+}
+
+func writeMemory(p *promWriter, mem metrics.MemoryStats, load metrics.LoadAvg) {
+	p.gauge("hidetop_memory_total_bytes", "Total physical memory.", float64(mem.Total))
+	p.gauge("hidetop_memory_used_bytes", "Used physical memory.", float64(mem.Used))
+	p.gauge("hidetop_memory_available_bytes", "Available physical memory.", float64(mem.Available))
+	p.gauge("hidetop_memory_used_percent", "Used physical memory as a percentage.", mem.Percent)
+	if mem.HasBreakdown() {
+		p.gauge("hidetop_memory_free_bytes", "Unused physical memory.", float64(mem.Free))
+		p.gauge("hidetop_memory_buffers_bytes", "Memory in block device buffers.", float64(mem.Buffers))
+		p.gauge("hidetop_memory_cached_bytes", "Memory in the page cache, shared memory included.", float64(mem.Cached))
+		p.gauge("hidetop_memory_shared_bytes", "Memory in tmpfs and shared memory.", float64(mem.Shared))
+		p.family("hidetop_memory_slab_bytes", "Kernel slab memory.", "gauge")
+		p.sample("hidetop_memory_slab_bytes", labels{"kind", "reclaimable"}, float64(mem.SlabReclaimable))
+		p.sample("hidetop_memory_slab_bytes", labels{"kind", "unreclaimable"}, float64(mem.SlabUnreclaimable))
+		p.gauge("hidetop_memory_dirty_bytes", "Memory waiting to be written back to disk.", float64(mem.Dirty))
+		p.gauge("hidetop_memory_writeback_bytes", "Memory being written back to disk.", float64(mem.Writeback))
+		p.gauge("hidetop_memory_committed_bytes", "Memory promised to processes (Committed_AS).", float64(mem.CommittedAS))
+		p.gauge("hidetop_memory_commit_limit_bytes", "Commit limit under strict overcommit.", float64(mem.CommitLimit))
+	}
+	if mem.HugePagesTotal > 0 {
+		p.gauge("hidetop_memory_hugepages_total_bytes", "Memory reserved for huge pages.", float64(mem.HugePagesTotal*mem.HugePageSize))
+		p.gauge("hidetop_memory_hugepages_free_bytes", "Unused huge page memory.", float64(mem.HugePagesFree*mem.HugePageSize))
+	}
+	p.gauge("hidetop_swap_total_bytes", "Total swap space.", float64(mem.SwapTotal))
+	p.gauge("hidetop_swap_used_bytes", "Used swap space.", float64(mem.SwapUsed))
+	p.gauge("hidetop_swap_used_percent", "Used swap space as a percentage.", mem.SwapPercent)
+
+	p.gauge("hidetop_load1", "1-minute load average.", load.Load1)
+	p.gauge("hidetop_load5", "5-minute load average.", load.Load5)
+	p.gauge("hidetop_load15", "15-minute load average.", load.Load15)
+}
+
+func writePressure(p *promWriter, psi metrics.PressureStats) {
+	if !psi.Available {
+		return
+	}
+	resources := []struct {
+		name string
+		p    metrics.Pressure
+	}{
+		{"cpu", psi.CPU}, {"memory", psi.Memory}, {"io", psi.IO},
+	}
+	p.family("hidetop_pressure_percent", "Pressure stall information: share of time tasks were stalled, averaged over a window.", "gauge")
+	for _, r := range resources {
+		for _, k := range []struct {
+			kind string
+			l    metrics.PressureLine
+		}{{"some", r.p.Some}, {"full", r.p.Full}} {
+			p.sample("hidetop_pressure_percent", labels{"resource", r.name, "kind", k.kind, "window", "10s"}, k.l.Avg10)
+			p.sample("hidetop_pressure_percent", labels{"resource", r.name, "kind", k.kind, "window", "60s"}, k.l.Avg60)
+			p.sample("hidetop_pressure_percent", labels{"resource", r.name, "kind", k.kind, "window", "300s"}, k.l.Avg300)
+		}
+	}
+	p.family("hidetop_pressure_stalled_seconds_total", "Pressure stall information: total time tasks were stalled.", "counter")
+	for _, r := range resources {
+		p.sample("hidetop_pressure_stalled_seconds_total", labels{"resource", r.name, "kind", "some"}, float64(r.p.Some.Total)/1e6)
+		p.sample("hidetop_pressure_stalled_seconds_total", labels{"resource", r.name, "kind", "full"}, float64(r.p.Full.Total)/1e6)
+	}
+}
+
+func writeNetwork(p *promWriter, net metrics.NetworkStats) {
+	if !net.Available {
+		return
+	}
+	p.family("hidetop_network_receive_bytes_total", "Bytes received per interface.", "counter")
+	for _, iface := range net.Interfaces {
+		p.sample("hidetop_network_receive_bytes_total", labels{"interface", iface.Name}, float64(iface.BytesIn))
+	}
+	p.family("hidetop_network_transmit_bytes_total", "Bytes sent per interface.", "counter")
+	for _, iface := range net.Interfaces {
+		p.sample("hidetop_network_transmit_bytes_total", labels{"interface", iface.Name}, float64(iface.BytesOut))
+	}
+}
+
+func writeDisk(p *promWriter, disk metrics.DiskStats) {
+	if !disk.Available {
+		return
+	}
+	devices := append([]metrics.DiskIOStats(nil), disk.Devices...)
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+
+	if len(devices) > 0 {
+		p.family("hidetop_disk_read_bytes_total", "Bytes read per block device.", "counter")
+		for _, d := range devices {
+			p.sample("hidetop_disk_read_bytes_total", labels{"device", d.Name}, float64(d.ReadBytes))
+		}
+		p.family("hidetop_disk_written_bytes_total", "Bytes written per block device.", "counter")
+		for _, d := range devices {
+			p.sample("hidetop_disk_written_bytes_total", labels{"device", d.Name}, float64(d.WriteBytes))
+		}
+	}
+	if disk.RootTotalGB > 0 {
+		p.gauge("hidetop_disk_root_used_bytes", "Used space on the root filesystem.", disk.RootUsedGB*gib)
+		p.gauge("hidetop_disk_root_total_bytes", "Size of the root filesystem.", disk.RootTotalGB*gib)
+		p.gauge("hidetop_disk_root_used_percent", "Used space on the root filesystem as a percentage.", disk.RootPercent)
+	}
+}
+
+func writeTemperature(p *promWriter, temp metrics.TemperatureStats) {
+	if !temp.Available {
+		return
+	}
+	p.family("hidetop_temperature_celsius", "Temperature per sensor.", "gauge")
+	// Labels repeat across chips (every socket has a "Core 0"), and
+	// Prometheus rejects duplicate series, so later ones get a " #n"
+	// suffix in the order the system lists them.
+	seen := make(map[string]int, len(temp.Sensors))
+	for _, s := range temp.Sensors {
+		sensor := s.Label
+		if seen[s.Label]++; seen[s.Label] > 1 {
+			sensor += " #" + strconv.Itoa(seen[s.Label])
+		}
+		p.sample("hidetop_temperature_celsius", labels{"sensor", sensor}, s.Temperature)
+	}
+	if temp.CPUTemp > 0 {
+		p.gauge("hidetop_temperature_cpu_celsius", "Best-effort CPU temperature (hottest CPU sensor).", temp.CPUTemp)
+	}
+	if temp.GPUTemp > 0 {
+		p.gauge("hidetop_temperature_gpu_celsius", "Best-effort GPU temperature (hottest GPU sensor).", temp.GPUTemp)
+	}
+}
+
+func writeGPU(p *promWriter, g *gpu.Stats) {
+	if g == nil || !g.Available {
+		return
+	}
+	p.family("hidetop_gpu_info", "GPU model and backend; always 1.", "gauge")
+	p.sample("hidetop_gpu_info", labels{"name", g.Name, "backend", gpu.BackendName()}, 1)
+	p.gauge("hidetop_gpu_utilization_percent", "GPU utilisation.", g.Utilization)
+	if g.FrequencyMHz > 0 {
+		p.gauge("hidetop_gpu_frequency_hertz", "GPU core clock.", float64(g.FrequencyMHz)*1e6)
+	}
+	if g.Temperature > 0 {
+		p.gauge("hidetop_gpu_temperature_celsius", "GPU temperature.", g.Temperature)
+	}
+	if g.MemoryTotalMB > 0 {
+		p.gauge("hidetop_gpu_memory_used_bytes", "Used GPU memory.", g.MemoryUsedMB*(1<<20))
+		p.gauge("hidetop_gpu_memory_total_bytes", "Total GPU memory.", g.MemoryTotalMB*(1<<20))
+	}
+	if len(g.Engines) > 0 {
+		p.family("hidetop_gpu_engine_utilization_percent", "Per-engine GPU utilisation.", "gauge")
+		for _, e := range g.Engines {
+			p.sample("hidetop_gpu_engine_utilization_percent", labels{"engine", e.Name}, e.Utilization)
+		}
+	}
+	if g.ThermalOK {
+		p.gauge("hidetop_thermal_pressure", "Thermal pressure level (0 nominal, 1 fair, 2 serious, 3 critical).", float64(g.Thermal))
+	}
+	if g.Energy.Available {
+		p.gauge("hidetop_energy_impact_score", "Heuristic energy impact score (0-100).", g.Energy.Score)
+	}
+}
+
+func writeBattery(p *promWriter, bat metrics.BatteryStats) {
+	if !bat.Available {
+		return
+	}
+	p.gauge("hidetop_battery_percent", "Battery charge.", bat.Percent)
+	p.gauge("hidetop_battery_charging", "Whether the battery is charging (1 = charging).", boolValue(bat.Charging))
+}
+
+func writeProcesses(p *promWriter, procs []metrics.ProcessInfo, topN int) {
+	p.gauge("hidetop_processes", "Number of processes in the snapshot.", float64(len(procs)))
+	if len(procs) == 0 {
+		return
+	}
+	top := append([]metrics.ProcessInfo(nil), procs...)
+	sort.SliceStable(top, func(i, j int) bool { return top[i].CPUPercent > top[j].CPUPercent })
+	if topN > 0 && topN < len(top) {
+		top = top[:topN]
+	}
+
+	procLabels := func(pr metrics.ProcessInfo) labels {
+		return labels{"pid", strconv.Itoa(int(pr.PID)), "name", pr.Name, "user", pr.User}
+	}
+	p.family("hidetop_process_cpu_percent", "CPU utilisation of the top processes by CPU.", "gauge")
+	for _, pr := range top {
+		p.sample("hidetop_process_cpu_percent", procLabels(pr), pr.CPUPercent)
+	}
+	p.family("hidetop_process_memory_percent", "Memory usage of the top processes by CPU.", "gauge")
+	for _, pr := range top {
+		p.sample("hidetop_process_memory_percent", procLabels(pr), float64(pr.MemPercent))
+	}
+	p.family("hidetop_process_threads", "Thread count of the top processes by CPU.", "gauge")
+	for _, pr := range top {
+		p.sample("hidetop_process_threads", procLabels(pr), float64(pr.NumThreads))
+	}
+	p.family("hidetop_process_read_bytes_per_second", "Storage read rate of the top processes by CPU.", "gauge")
+	for _, pr := range top {
+		p.sample("hidetop_process_read_bytes_per_second", procLabels(pr), pr.ReadRate)
+	}
+	p.family("hidetop_process_write_bytes_per_second", "Storage write rate of the top processes by CPU.", "gauge")
+	for _, pr := range top {
+		p.sample("hidetop_process_write_bytes_per_second", procLabels(pr), pr.WriteRate)
+	}
+}
+
+func writeExtra(p *promWriter, extra map[string]float64) {
+	if len(extra) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	p.family("hidetop_extra", "Values reported by additional registered collectors.", "gauge")
+	for _, k := range keys {
+		p.sample("hidetop_extra", labels{"name", k}, extra[k])
+	}
+}
+
+// labels is a flat list of alternating label names and values, kept in
+// the order given so output is deterministic.
+type labels []string
+
+// promWriter accumulates the first write error so callers can write
+// unconditionally and check once at the end.
+type promWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *promWriter) family(name, help, typ string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (p *promWriter) gauge(name, help string, v float64) {
+	p.family(name, help, "gauge")
+	p.sample(name, nil, v)
+}
+
+func (p *promWriter) sample(name string, l labels, v float64) {
+	if len(l) == 0 {
+		p.printf("%s %s\n", name, formatValue(v))
+		return
+	}
+	var b strings.Builder
+	for i := 0; i+1 < len(l); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(l[i+1]))
+		b.WriteByte('"')
+	}
+	p.printf("%s{%s} %s\n", name, b.String(), formatValue(v))
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/metrics/gpu"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	snap := metrics.Snapshot{
+		CollectedAt: now,
+		CPU: metrics.CPUStats{PerCore: []float64{10, 30}, Total: 20,
+			PerCoreTimes: make([]metrics.CPUTimes, 2), Times: metrics.CPUTimes{User: 15, Steal: 5, Idle: 80},
+			Cores: []metrics.CPUCore{{CPU: 0, MHz: 800}, {CPU: 1, MHz: 4700}}},
+		Memory: metrics.MemoryStats{Total: 2 << 30, Percent: 50, Cached: 1 << 20, SlabReclaimable: 4096},
+		Pressure: metrics.PressureStats{Available: true,
+			IO: metrics.Pressure{Some: metrics.PressureLine{Avg60: 1.5, Total: 2500000}}},
+		Temperature: metrics.TemperatureStats{Available: true, Sensors: []metrics.SensorReading{
+			{Label: "Core 0", Temperature: 50}, {Label: "Package", Temperature: 55}, {Label: "Core 0", Temperature: 60},
+		}},
+		GPU: &gpu.Stats{Available: true, Name: "Test GPU", FrequencyMHz: 1500},
+		Network: metrics.NetworkStats{
+			Available:  true,
+			Interfaces: []metrics.InterfaceStats{{Name: "eth0", BytesIn: 100, BytesOut: 200}},
+		},
+		Processes: []metrics.ProcessInfo{
+			{PID: 1, Name: "idle", User: "root", CPUPercent: 0.5},
+			{PID: 42, Name: `we"ird`, User: "bob", CPUPercent: 90},
+		},
+		Status:    metrics.CollectionStatus{"temp": {Stale: true}},
+		SampledAt: map[string]time.Time{"cpu": now, "temp": now.Add(-time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, snap, 1); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE hidetop_cpu_usage_percent gauge\nhidetop_cpu_usage_percent 20\n",
+		`hidetop_cpu_core_usage_percent{core="1"} 30`,
+		`hidetop_cpu_mode_percent{mode="steal"} 5`,
+		`hidetop_cpu_core_frequency_hertz{core="1"} 4.7e+09`,
+		"hidetop_memory_total_bytes 2.147483648e+09",
+		"hidetop_memory_cached_bytes 1.048576e+06",
+		`hidetop_memory_slab_bytes{kind="reclaimable"} 4096`,
+		`hidetop_pressure_percent{resource="io",kind="some",window="60s"} 1.5`,
+		`hidetop_pressure_stalled_seconds_total{resource="io",kind="some"} 2.5`,
+		`hidetop_temperature_celsius{sensor="Core 0"} 50`,
+		`hidetop_temperature_celsius{sensor="Core 0 #2"} 60`,
+		"hidetop_gpu_frequency_hertz 1.5e+09",
+		"# TYPE hidetop_network_receive_bytes_total counter",
+		`hidetop_network_transmit_bytes_total{interface="eth0"} 200`,
+		`hidetop_process_cpu_percent{pid="42",name="we\"ird",user="bob"} 90`,
+		`hidetop_collector_stale{collector="temp"} 1`,
+		`hidetop_collector_stale{collector="cpu"} 0`,
+		"hidetop_processes 2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(out, `pid="1"`) {
+		t.Errorf("expected topN=1 to drop the idle process")
+	}
+	if strings.Contains(out, "hidetop_memory_hugepages_total_bytes") {
+		t.Errorf("expected huge pages to be omitted when none are reserved")
+	}
+	if strings.Contains(out, "hidetop_battery_percent") {
+		t.Errorf("expected unavailable battery to be omitted")
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\\b\"c\nd"); got != `a\\b\"c\nd` {
+		t.Fatalf("unexpected escaping: %q", got)
+	}
+}
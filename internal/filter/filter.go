@@ -0,0 +1,370 @@
+// Package filter parses the process filter language of the search
+// prompt and matches processes against it.
+//
+// A filter is a list of terms that must all match, e.g.
+//
+//	user:postgres cpu>5 state:Z name~^java
+//
+// Terms can be joined with "or" (or "|"), grouped with parentheses and
+// negated with a leading "!" or "not". A term is <field><op><value>:
+//
+//	:         contains (text, case-insensitive) or equals (numbers, state)
+//	= !=      equals, differs
+//	~ !~      matches, does not match a regular expression
+//	> >= < <= compares numbers
+//
+// Numbers accept a K/M/G suffix (powers of 1024), e.g. rss>500M. Values
+// with spaces are double-quoted. A bare word matches the name, user or
+// PID like the plain search, and @name expands a named filter from the
+// config file.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	match   matcher
+	details bool
+}
+
+type matcher func(p *metrics.ProcessInfo) bool
+
+// Match reports whether p matches the filter.
+func (f *Filter) Match(p metrics.ProcessInfo) bool {
+	return f.match(&p)
+}
+
+// NeedsDetails reports whether the filter uses a ProcessDetails field
+// (nice, prio, time, fds, cmd), which is only read on demand.
+func (f *Filter) NeedsDetails() bool { return f.details }
+
+// field reads one ProcessInfo field. Exactly one of text and num is set.
+type field struct {
+	text   func(p *metrics.ProcessInfo) string
+	num    func(p *metrics.ProcessInfo) float64
+	detail bool // a ProcessDetails field
+}
+
+// fields are the filterable fields, named like the process columns.
+var fields = map[string]field{
+	"pid":       {num: func(p *metrics.ProcessInfo) float64 { return float64(p.PID) }},
+	"ppid":      {num: func(p *metrics.ProcessInfo) float64 { return float64(p.PPID) }},
+	"name":      {text: func(p *metrics.ProcessInfo) string { return p.Name }},
+	"user":      {text: func(p *metrics.ProcessInfo) string { return p.User }},
+	"state":     {text: func(p *metrics.ProcessInfo) string { return p.State }},
+	"threads":   {num: func(p *metrics.ProcessInfo) float64 { return float64(p.NumThreads) }},
+	"cpu":       {num: func(p *metrics.ProcessInfo) float64 { return p.CPUPercent }},
+	"mem":       {num: func(p *metrics.ProcessInfo) float64 { return float64(p.MemPercent) }},
+	"rss":       {num: func(p *metrics.ProcessInfo) float64 { return float64(p.RSS) }},
+	"vsz":       {num: func(p *metrics.ProcessInfo) float64 { return float64(p.VMS) }},
+	"io_r":      {num: func(p *metrics.ProcessInfo) float64 { return p.ReadRate }},
+	"io_w":      {num: func(p *metrics.ProcessInfo) float64 { return p.WriteRate }},
+	"container": {text: func(p *metrics.ProcessInfo) string { return p.Container }},
+	"unit":      {text: func(p *metrics.ProcessInfo) string { return p.Unit }},
+	"cgroup":    {text: func(p *metrics.ProcessInfo) string { return p.Cgroup }},
+	"nice":      {num: func(p *metrics.ProcessInfo) float64 { return float64(p.Nice) }, detail: true},
+	"prio":      {num: func(p *metrics.ProcessInfo) float64 { return float64(p.Priority) }, detail: true},
+	"time":      {num: func(p *metrics.ProcessInfo) float64 { return p.CPUTime }, detail: true},
+	"fds":       {num: func(p *metrics.ProcessInfo) float64 { return float64(p.NumFDs) }, detail: true},
+	"cmd":       {text: func(p *metrics.ProcessInfo) string { return p.Cmdline }, detail: true},
+}
+
+// FieldNames returns the filterable field names, sorted.
+func FieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ops are the term operators, longest first so ">=" is not read as ">".
+var ops = []string{">=", "<=", "!=", "!~", ":", "=", "~", ">", "<"}
+
+// Parse parses a filter expression. named holds the named filters that
+// @name refers to. An empty expression matches every process.
+func Parse(expr string, named map[string]string) (*Filter, error) {
+	p := parser{named: named, expanding: map[string]bool{}}
+	return p.parse(expr)
+}
+
+type parser struct {
+	toks      []token
+	pos       int
+	named     map[string]string
+	expanding map[string]bool // named filters being expanded, to stop cycles
+	details   bool
+}
+
+func (p *parser) parse(expr string) (*Filter, error) {
+	toks, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p.toks, p.pos = toks, 0
+	if len(toks) == 0 {
+		return &Filter{match: func(*metrics.ProcessInfo) bool { return true }}, nil
+	}
+	m, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	return &Filter{match: m, details: p.details}, nil
+}
+
+// or = and { "or" and }
+func (p *parser) or() (matcher, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(tokOr) {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(pi *metrics.ProcessInfo) bool { return l(pi) || right(pi) }
+	}
+	return left, nil
+}
+
+// and = unary { ["and"] unary }
+func (p *parser) and() (matcher, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.toks) && !p.peek(tokOr) && !p.peek(tokRParen) {
+		if p.peek(tokAnd) {
+			p.pos++
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(pi *metrics.ProcessInfo) bool { return l(pi) && right(pi) }
+	}
+	return left, nil
+}
+
+// unary = "not" unary | "(" or ")" | term
+func (p *parser) unary() (matcher, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("expression ends early")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch t.kind {
+	case tokNot:
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(pi *metrics.ProcessInfo) bool { return !m(pi) }, nil
+	case tokLParen:
+		m, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tokRParen) {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return m, nil
+	case tokTerm:
+		return p.term(t.text)
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (p *parser) peek(kind tokKind) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == kind
+}
+
+// term parses a single <field><op><value>, @name or bare word.
+func (p *parser) term(text string) (matcher, error) {
+	if name, ok := strings.CutPrefix(text, "@"); ok {
+		return p.expand(name)
+	}
+	name, op, value, ok := splitTerm(text)
+	if !ok {
+		return bareWord(unquote(text)), nil
+	}
+	f, known := fields[name]
+	if !known {
+		return nil, fmt.Errorf("unknown field %q (known: %s)", name, strings.Join(FieldNames(), ", "))
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%s%s: missing value", name, op)
+	}
+	p.details = p.details || f.detail
+	value = unquote(value)
+
+	if op == "~" || op == "!~" {
+		if f.text == nil {
+			return nil, fmt.Errorf("%s: ~ needs a text field", name)
+		}
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		get := f.text
+		want := op == "~"
+		return func(pi *metrics.ProcessInfo) bool { return re.MatchString(get(pi)) == want }, nil
+	}
+	if name == "state" {
+		return stateTerm(op, value)
+	}
+	if f.text != nil {
+		return textTerm(name, f.text, op, value)
+	}
+	return numTerm(name, f.num, op, value)
+}
+
+// expand parses a named filter as a parenthesised sub-expression.
+func (p *parser) expand(name string) (matcher, error) {
+	expr, ok := p.named[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown filter @%s", name)
+	}
+	if p.expanding[name] {
+		return nil, fmt.Errorf("filter @%s refers to itself", name)
+	}
+	p.expanding[name] = true
+	defer delete(p.expanding, name)
+
+	sub := parser{named: p.named, expanding: p.expanding}
+	f, err := sub.parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("@%s: %w", name, err)
+	}
+	p.details = p.details || f.details
+	return f.match, nil
+}
+
+// splitTerm splits "cpu>=5" into "cpu", ">=", "5". ok is false for a
+// bare word.
+func splitTerm(text string) (name, op, value string, ok bool) {
+	i := 0
+	for i < len(text) && (text[i] == '_' || text[i] >= 'a' && text[i] <= 'z' || text[i] >= 'A' && text[i] <= 'Z') {
+		i++
+	}
+	if i == 0 {
+		return "", "", "", false
+	}
+	for _, op := range ops {
+		if strings.HasPrefix(text[i:], op) {
+			return strings.ToLower(text[:i]), op, text[i+len(op):], true
+		}
+	}
+	return "", "", "", false
+}
+
+// bareWord matches like the plain search: a case-insensitive substring
+// of the name or user, or of the PID.
+func bareWord(word string) matcher {
+	word = strings.ToLower(word)
+	return func(p *metrics.ProcessInfo) bool {
+		return strings.Contains(strings.ToLower(p.Name), word) ||
+			strings.Contains(strings.ToLower(p.User), word) ||
+			strings.Contains(strconv.Itoa(int(p.PID)), word)
+	}
+}
+
+// stateTerm matches a state by its letter (Z) or name (zombie).
+func stateTerm(op, value string) (matcher, error) {
+	var want bool
+	switch op {
+	case ":", "=":
+		want = true
+	case "!=":
+	default:
+		return nil, fmt.Errorf("state: %s not supported", op)
+	}
+	return func(p *metrics.ProcessInfo) bool {
+		is := strings.EqualFold(metrics.StateLetter(p.State), value) || strings.EqualFold(p.State, value)
+		return is == want
+	}, nil
+}
+
+func textTerm(name string, get func(*metrics.ProcessInfo) string, op, value string) (matcher, error) {
+	switch op {
+	case ":":
+		value = strings.ToLower(value)
+		return func(p *metrics.ProcessInfo) bool { return strings.Contains(strings.ToLower(get(p)), value) }, nil
+	case "=":
+		return func(p *metrics.ProcessInfo) bool { return strings.EqualFold(get(p), value) }, nil
+	case "!=":
+		return func(p *metrics.ProcessInfo) bool { return !strings.EqualFold(get(p), value) }, nil
+	}
+	return nil, fmt.Errorf("%s: %s needs a numeric field", name, op)
+}
+
+func numTerm(name string, get func(*metrics.ProcessInfo) float64, op, value string) (matcher, error) {
+	v, err := parseNumber(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	switch op {
+	case ":", "=":
+		return func(p *metrics.ProcessInfo) bool { return get(p) == v }, nil
+	case "!=":
+		return func(p *metrics.ProcessInfo) bool { return get(p) != v }, nil
+	case ">":
+		return func(p *metrics.ProcessInfo) bool { return get(p) > v }, nil
+	case ">=":
+		return func(p *metrics.ProcessInfo) bool { return get(p) >= v }, nil
+	case "<":
+		return func(p *metrics.ProcessInfo) bool { return get(p) < v }, nil
+	case "<=":
+		return func(p *metrics.ProcessInfo) bool { return get(p) <= v }, nil
+	}
+	return nil, fmt.Errorf("%s: %s not supported", name, op)
+}
+
+// parseNumber parses a number with an optional K/M/G (powers of 1024)
+// or % suffix.
+func parseNumber(s string) (float64, error) {
+	mult := 1.0
+	num := strings.TrimSuffix(s, "%")
+	if n := len(num); n > 0 {
+		switch num[n-1] {
+		case 'k', 'K':
+			mult, num = 1<<10, num[:n-1]
+		case 'm', 'M':
+			mult, num = 1<<20, num[:n-1]
+		case 'g', 'G':
+			mult, num = 1<<30, num[:n-1]
+		}
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad number %q", s)
+	}
+	return v * mult, nil
+}
+
+// unquote strips the double quotes of a quoted value.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+var procs = []metrics.ProcessInfo{
+	{PID: 1, Name: "systemd", User: "root", State: "sleeping", CPUPercent: 0.1, RSS: 12 << 20},
+	{PID: 200, Name: "postgres", User: "postgres", State: "running", CPUPercent: 12, RSS: 600 << 20},
+	{PID: 201, Name: "postgres", User: "postgres", State: "sleeping", CPUPercent: 0, RSS: 80 << 20},
+	{PID: 300, Name: "java", User: "app", State: "zombie", CPUPercent: 7},
+	{PID: 301, Name: "javac", User: "app", State: "sleeping", CPUPercent: 3,
+		ProcessDetails: metrics.ProcessDetails{Cmdline: "javac -d out Main.java"}},
+}
+
+// pids returns the PIDs of procs matching expr.
+func pids(t *testing.T, expr string, named map[string]string) []int32 {
+	t.Helper()
+	f, err := Parse(expr, named)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", expr, err)
+	}
+	var got []int32
+	for _, p := range procs {
+		if f.Match(p) {
+			got = append(got, p.PID)
+		}
+	}
+	return got
+}
+
+func TestParse_Matches(t *testing.T) {
+	named := map[string]string{"db": "user:postgres", "busy": "cpu>5"}
+	tests := []struct {
+		expr string
+		want []int32
+	}{
+		{"", []int32{1, 200, 201, 300, 301}},
+		{"post", []int32{200, 201}},
+		{"20", []int32{200, 201}},
+		{"user:postgres cpu>5", []int32{200}},
+		{"state:Z", []int32{300}},
+		{"state:zombie", []int32{300}},
+		{"name~^java", []int32{300, 301}},
+		{"name~^java$", []int32{300}},
+		{"name!~^java", []int32{1, 200, 201}},
+		{"name=JAVA", []int32{300}},
+		{"rss>=80M rss<1G", []int32{200, 201}},
+		{"!user:root pid<300", []int32{200, 201}},
+		{"not user:postgres and not user:app", []int32{1}},
+		{"state:Z or cpu>10", []int32{200, 300}},
+		{"(name:java | name:systemd) cpu<5", []int32{1, 301}},
+		{"name~^(java|systemd)$", []int32{1, 300}},
+		{`cmd:"-d out"`, []int32{301}},
+		{"@db @busy", []int32{200}},
+		{"@busy or state:Z", []int32{200, 300}},
+	}
+	for _, tt := range tests {
+		got := pids(t, tt.expr, named)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	named := map[string]string{"loop": "@loop", "bad": "cpu>"}
+	tests := []struct{ expr, want string }{
+		{"bogus:1", "unknown field"},
+		{"cpu>", "missing value"},
+		{"cpu>lots", "bad number"},
+		{"name>5", "numeric field"},
+		{"cpu~1", "text field"},
+		{"name~(", "missing closing )"},
+		{"(cpu>5", "missing )"},
+		{"cpu>5)", "unexpected"},
+		{`name:"x`, "unterminated quote"},
+		{"@nope", "unknown filter"},
+		{"@loop", "refers to itself"},
+		{"@bad", "@bad: cpu>: missing value"},
+		{"cpu>5 or", "ends early"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr, named)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestNeedsDetails(t *testing.T) {
+	for expr, want := range map[string]bool{
+		"cpu>5":              false,
+		"name:x cmd:java":    true,
+		"@c":                 true,
+		"not fds>100 or x":   true,
+		"user:root state:R ": false,
+	} {
+		f, err := Parse(expr, map[string]string{"c": "cmd:x"})
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", expr, err)
+		}
+		if f.NeedsDetails() != want {
+			t.Errorf("Parse(%q).NeedsDetails() = %v, want %v", expr, f.NeedsDetails(), want)
+		}
+	}
+}
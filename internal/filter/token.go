@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokKind int
+
+const (
+	tokTerm tokKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+// tokenize splits a filter expression into tokens. A term runs to the
+// next space or unbalanced ")", so regular expressions may contain
+// balanced parentheses and "|", and quoted values may contain spaces.
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case ' ', '\t':
+			i++
+		case '(':
+			toks = append(toks, token{tokLParen, "("})
+			i++
+		case ')':
+			toks = append(toks, token{tokRParen, ")"})
+			i++
+		case '|':
+			toks = append(toks, token{tokOr, "|"})
+			i++
+		case '&':
+			toks = append(toks, token{tokAnd, "&"})
+			i++
+		case '!':
+			toks = append(toks, token{tokNot, "!"})
+			i++
+		default:
+			end, err := termEnd(s, i)
+			if err != nil {
+				return nil, err
+			}
+			text := s[i:end]
+			switch strings.ToLower(text) {
+			case "or":
+				toks = append(toks, token{tokOr, text})
+			case "and":
+				toks = append(toks, token{tokAnd, text})
+			case "not":
+				toks = append(toks, token{tokNot, text})
+			default:
+				toks = append(toks, token{tokTerm, text})
+			}
+			i = end
+		}
+	}
+	return toks, nil
+}
+
+// termEnd returns the end of the term starting at s[start].
+func termEnd(s string, start int) (int, error) {
+	depth := 0
+	quoted := false
+	i := start
+	for ; i < len(s); i++ {
+		c := s[i]
+		if quoted {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				quoted = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			quoted = true
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		case ' ', '\t':
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	if quoted {
+		return 0, fmt.Errorf("unterminated quote")
+	}
+	return i, nil
+}
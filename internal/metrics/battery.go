@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // BatteryStats holds battery information.
@@ -18,6 +19,25 @@ type BatteryStats struct {
 	Status    string // "Charging", "Discharging", "Full", "Not charging"
 }
 
+func init() {
+	Register(&collectorFuncs{
+		name:  "bat",
+		every: 30 * time.Second,
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			b, err := CollectBattery(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Battery = b }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if previous.Battery.Available {
+				snap.Battery = previous.Battery
+			}
+		},
+	})
+}
+
 // CollectBattery gathers battery information.
 // Works on Linux (sysfs) and macOS (pmset).
 func CollectBattery(ctx context.Context) (BatteryStats, error) {
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// readCgroup returns the cgroup path of pid, or "" where cgroups are not
+// available.
+func readCgroup(pid int32) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return ""
+	}
+	return parseCgroup(string(data))
+}
+
+// parseCgroup picks the most useful path from /proc/<pid>/cgroup: the
+// unified (v2) hierarchy if present, otherwise the v1 systemd or cpu
+// controller.
+func parseCgroup(data string) string {
+	var v1 string
+	for _, line := range strings.Split(data, "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			return parts[2]
+		case parts[1] == "name=systemd":
+			v1 = parts[2]
+		case v1 == "" && strings.Contains(","+parts[1]+",", ",cpu,"):
+			v1 = parts[2]
+		}
+	}
+	return v1
+}
+
+// containerPrefixes maps scope-name prefixes used by systemd-managed
+// container runtimes to a runtime name.
+var containerPrefixes = []struct{ prefix, runtime string }{
+	{"docker-", "docker"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+	{"libpod-", "podman"},
+}
+
+// classifyCgroup derives a container ID ("runtime/shortid") and systemd
+// unit from a cgroup path. Either may be empty.
+func classifyCgroup(path string) (container, unit string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		if container == "" {
+			container = containerID(parts, i)
+		}
+		if unit == "" && container == "" &&
+			(strings.HasSuffix(part, ".service") || strings.HasSuffix(part, ".scope")) {
+			unit = part
+		}
+	}
+	return container, unit
+}
+
+// containerID recognises a container at parts[i]: either a runtime
+// scope ("docker-<id>.scope") or a bare ID under a runtime directory
+// ("/docker/<id>", "/kubepods/.../<id>", "/lxc/<name>").
+func containerID(parts []string, i int) string {
+	part := strings.TrimSuffix(parts[i], ".scope")
+	for _, cp := range containerPrefixes {
+		if id, ok := strings.CutPrefix(part, cp.prefix); ok && isHexID(id) {
+			return cp.runtime + "/" + shortID(id)
+		}
+	}
+	if i == 0 {
+		return ""
+	}
+	switch parent := parts[i-1]; {
+	case parent == "docker" && isHexID(part):
+		return "docker/" + shortID(part)
+	case parent == "lxc" || parent == "lxc.payload":
+		return "lxc/" + part
+	case strings.HasPrefix(parts[0], "kubepods") && isHexID(part):
+		return "k8s/" + shortID(part)
+	}
+	return ""
+}
+
+func isHexID(s string) bool {
+	if len(s) < 12 {
+		return false
+	}
+	for _, r := range s {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// shortID truncates a container ID the way docker ps does.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
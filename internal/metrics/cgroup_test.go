@@ -0,0 +1,41 @@
+package metrics
+
+import "testing"
+
+func TestParseCgroup(t *testing.T) {
+	v2 := "0::/system.slice/nginx.service\n"
+	if got := parseCgroup(v2); got != "/system.slice/nginx.service" {
+		t.Errorf("v2: got %q", got)
+	}
+	v1 := "12:cpu,cpuacct:/docker/abc\n1:name=systemd:/system.slice/docker-abc.scope\n"
+	if got := parseCgroup(v1); got != "/system.slice/docker-abc.scope" {
+		t.Errorf("v1: got %q", got)
+	}
+	if got := parseCgroup(""); got != "" {
+		t.Errorf("empty: got %q", got)
+	}
+}
+
+func TestClassifyCgroup(t *testing.T) {
+	const id = "3f2a1b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"
+	tests := []struct {
+		path, container, unit string
+	}{
+		{"/system.slice/nginx.service", "", "nginx.service"},
+		{"/user.slice/user-1000.slice/session-2.scope", "", "session-2.scope"},
+		{"/system.slice/docker-" + id + ".scope", "docker/3f2a1b4c5d6e", ""},
+		{"/docker/" + id, "docker/3f2a1b4c5d6e", ""},
+		{"/machine.slice/libpod-" + id + ".scope/container", "podman/3f2a1b4c5d6e", ""},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1.slice/cri-containerd-" + id + ".scope", "containerd/3f2a1b4c5d6e", ""},
+		{"/kubepods/besteffort/pod1/" + id, "k8s/3f2a1b4c5d6e", ""},
+		{"/lxc.payload/web", "lxc/web", ""},
+		{"/system.slice/docker-" + id + ".scope/system.slice/cron.service", "docker/3f2a1b4c5d6e", "cron.service"},
+		{"/", "", ""},
+	}
+	for _, tt := range tests {
+		c, u := classifyCgroup(tt.path)
+		if c != tt.container || u != tt.unit {
+			t.Errorf("classifyCgroup(%q) = %q, %q; want %q, %q", tt.path, c, u, tt.container, tt.unit)
+		}
+	}
+}
@@ -2,200 +2,109 @@ package metrics
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
 	"github.com/youhide/hideTop/internal/metrics/gpu"
 )
 
-// CollectOptions controls which metrics to skip.
+// CollectOptions controls which metrics to skip and how often to sample.
 type CollectOptions struct {
 	SkipGPU  bool
 	SkipTemp bool
+
+	// Intervals overrides the sampling interval of collectors by name
+	// (e.g. "temp": 10s). Collectors that ask to be sampled immediately,
+	// such as processes after a sort change, are not delayed by it.
+	Intervals map[string]time.Duration
+
+	// SortReverse flips the default direction of the process sort
+	// field, which decides which processes get their details read.
+	SortReverse bool
+}
+
+// Request carries the per-tick parameters handed to every Collector.
+type Request struct {
+	Now                time.Time
+	SortBy             SortField
+	DetailLimit        int // processes, in sort order, whose ProcessDetails are read
+	ProcessSampleEvery time.Duration
+	Previous           Snapshot
+	Options            CollectOptions
 }
 
+// Collect runs every enabled registered collector that is due
+// concurrently and assembles the results into a Snapshot. Collectors that
+// are not due yet, or that fail, fall back to their previous values
+// according to their own policy; failures are marked stale in
+// Snapshot.Status. Snapshot.SampledAt records when each value was taken.
+// Snapshot.Processes holds the whole process table; only the first
+// detailLimit processes in sort order have their ProcessDetails read.
 func Collect(
 	ctx context.Context,
-	cpuInterval time.Duration,
 	sortBy SortField,
-	procLimit int,
+	detailLimit int,
 	processSampleEvery time.Duration,
 	previous Snapshot,
 	opts CollectOptions,
 ) Snapshot {
 	now := time.Now()
+	req := Request{
+		Now:                now,
+		SortBy:             sortBy,
+		DetailLimit:        detailLimit,
+		ProcessSampleEvery: processSampleEvery,
+		Previous:           previous,
+		Options:            opts,
+	}
 
 	var (
 		wg   sync.WaitGroup
 		snap = Snapshot{
-			CollectedAt:     now,
-			ProcessSampleAt: previous.ProcessSampleAt,
-			ProcessSortBy:   previous.ProcessSortBy,
+			CollectedAt:        now,
+			ProcessSortBy:      previous.ProcessSortBy,
+			ProcessSortReverse: previous.ProcessSortReverse,
+			Status:             CollectionStatus{},
+			SampledAt:          make(map[string]time.Time),
 		}
 		mu sync.Mutex
 	)
 
-	processesDue := shouldCollectProcesses(now, processSampleEvery, sortBy, previous)
-
-	// Count workers: cpu + memory + load + network + disk + battery = 6
-	workers := 6
-	if !opts.SkipTemp {
-		workers++
-	}
-	if !opts.SkipGPU {
-		workers++
-	}
-	if processesDue {
-		workers++
-	} else {
-		snap.Processes = previous.Processes
-		snap.ProcessSampleAt = previous.ProcessSampleAt
-		snap.ProcessSortBy = sortBy
-	}
-
-	wg.Add(workers)
-
-	if !opts.SkipTemp {
-		go func() {
-			defer wg.Done()
-			t, err := CollectTemperature(ctx)
-			mu.Lock()
-			defer mu.Unlock()
-			if err != nil {
-				snap.Status.Temperature = staleStatus(err)
-				if previous.Temperature.Available {
-					snap.Temperature = previous.Temperature
-				}
-				return
-			}
-			snap.Temperature = t
-		}()
-	}
-
-	go func() {
-		defer wg.Done()
-		c, err := CollectCPU(ctx, cpuInterval)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.CPU = staleStatus(err)
-			if !previous.CollectedAt.IsZero() {
-				snap.CPU = previous.CPU
-			}
-			return
-		}
-		snap.CPU = c
-	}()
-
-	go func() {
-		defer wg.Done()
-		m, err := CollectMemory(ctx)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.Memory = staleStatus(err)
-			if !previous.CollectedAt.IsZero() {
-				snap.Memory = previous.Memory
-			}
-			return
-		}
-		snap.Memory = m
-	}()
-
-	go func() {
-		defer wg.Done()
-		l, err := CollectLoad(ctx)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.Load = staleStatus(err)
-			if !previous.CollectedAt.IsZero() {
-				snap.Load = previous.Load
-			}
-			return
-		}
-		snap.Load = l
-	}()
-
-	go func() {
-		defer wg.Done()
-		n, err := CollectNetwork(ctx)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.Network = staleStatus(err)
-			if previous.Network.Available {
-				snap.Network = previous.Network
-			}
-			return
-		}
-		snap.Network = n
-	}()
-
-	go func() {
-		defer wg.Done()
-		d, err := CollectDisk(ctx)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.Disk = staleStatus(err)
-			if previous.Disk.Available {
-				snap.Disk = previous.Disk
-			}
-			return
+	for _, c := range Collectors() {
+		if !c.Enabled(opts) {
+			continue
 		}
-		snap.Disk = d
-	}()
-
-	go func() {
-		defer wg.Done()
-		b, err := CollectBattery(ctx)
-		mu.Lock()
-		defer mu.Unlock()
-		if err != nil {
-			snap.Status.Battery = staleStatus(err)
-			if previous.Battery.Available {
-				snap.Battery = previous.Battery
+		name := c.Name()
+		last := previous.SampledAt[name]
+		if !collectorDue(c, req, last) {
+			// Collectors started earlier may already be writing snap.
+			mu.Lock()
+			c.Fallback(&snap, previous)
+			snap.SampledAt[name] = last
+			if st, ok := previous.Status[name]; ok {
+				snap.Status[name] = st
 			}
-			return
+			mu.Unlock()
+			continue
 		}
-		snap.Battery = b
-	}()
-
-	if processesDue {
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			p, err := CollectProcesses(ctx, sortBy, procLimit)
+			apply, err := c.Collect(ctx, req)
 			mu.Lock()
 			defer mu.Unlock()
 			if err != nil {
-				snap.Status.Processes = staleStatus(err)
-				if len(previous.Processes) > 0 {
-					snap.Processes = previous.Processes
-					snap.ProcessSampleAt = previous.ProcessSampleAt
-					snap.ProcessSortBy = previous.ProcessSortBy
+				snap.Status[name] = staleStatus(err)
+				c.Fallback(&snap, previous)
+				if !last.IsZero() {
+					snap.SampledAt[name] = last
 				}
 				return
 			}
-			snap.Processes = p
-			snap.ProcessSampleAt = now
-			snap.ProcessSortBy = sortBy
-		}()
-	}
-
-	if !opts.SkipGPU {
-		go func() {
-			defer wg.Done()
-			g := gpu.Collect(ctx, 0) // cpuTotal not needed for raw GPU metrics
-			mu.Lock()
-			defer mu.Unlock()
-			if g.Available {
-				snap.GPU = &g
-			} else if previous.GPU != nil && previous.GPU.Available {
-				snap.GPU = previous.GPU
-				snap.Status.GPU = staleStatus(errors.New("collector unavailable"))
+			if apply != nil {
+				apply(&snap)
 			}
+			snap.SampledAt[name] = now
 		}()
 	}
 
@@ -210,17 +119,20 @@ func Collect(
 	return snap
 }
 
-func shouldCollectProcesses(now time.Time, interval time.Duration, sortBy SortField, previous Snapshot) bool {
-	if len(previous.Processes) == 0 || previous.ProcessSampleAt.IsZero() {
+// collectorDue reports whether c should sample on this tick given the
+// time of its last successful sample.
+func collectorDue(c Collector, req Request, last time.Time) bool {
+	if last.IsZero() {
 		return true
 	}
-	if previous.ProcessSortBy != sortBy {
+	interval := c.Interval(req)
+	if interval == SampleNow {
 		return true
 	}
-	if interval <= 0 {
-		return true
+	if override, ok := req.Options.Intervals[c.Name()]; ok {
+		interval = override
 	}
-	return now.Sub(previous.ProcessSampleAt) >= interval
+	return interval <= 0 || req.Now.Sub(last) >= interval
 }
 
 func staleStatus(err error) MetricStatus {
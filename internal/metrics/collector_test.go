@@ -1,33 +1,74 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 	"time"
 )
 
-func TestShouldCollectProcesses(t *testing.T) {
+func TestCollectorDue_Processes(t *testing.T) {
 	now := time.Now()
+	last := now.Add(-time.Second)
 	previous := Snapshot{
-		Processes:       []ProcessInfo{{PID: 1}},
-		ProcessSampleAt: now.Add(-time.Second),
-		ProcessSortBy:   SortByCPU,
+		Processes:     []ProcessInfo{{PID: 1}},
+		ProcessSortBy: SortByCPU,
+		SampledAt:     map[string]time.Time{"proc": last},
 	}
+	proc := &collectorFuncs{name: "proc", interval: processInterval}
+	req := Request{Now: now, SortBy: SortByCPU, ProcessSampleEvery: 2 * time.Second, Previous: previous}
 
-	if shouldCollectProcesses(now, 2*time.Second, SortByCPU, previous) {
+	if collectorDue(proc, req, last) {
 		t.Fatalf("expected cached processes to be reused before sampling window")
 	}
-	if !shouldCollectProcesses(now, 2*time.Second, SortByMem, previous) {
+	req.SortBy = SortByMem
+	if !collectorDue(proc, req, last) {
 		t.Fatalf("expected recollection when sort field changes")
 	}
-	if !shouldCollectProcesses(now, 2*time.Second, SortByCPU, Snapshot{}) {
+	req.Options.Intervals = map[string]time.Duration{"proc": time.Minute}
+	if !collectorDue(proc, req, last) {
+		t.Fatalf("expected a configured interval not to delay recollection after a sort change")
+	}
+	req = Request{Now: now, SortBy: SortByCPU, ProcessSampleEvery: 2 * time.Second}
+	if !collectorDue(proc, req, time.Time{}) {
 		t.Fatalf("expected initial collection when no previous sample exists")
 	}
 }
 
+func TestCollectorDue_FixedIntervalAndOverride(t *testing.T) {
+	now := time.Now()
+	bat := &collectorFuncs{name: "bat", every: 30 * time.Second}
+	req := Request{Now: now}
+
+	if collectorDue(bat, req, now.Add(-10*time.Second)) {
+		t.Fatalf("expected battery to wait for its 30s cadence")
+	}
+	if !collectorDue(bat, req, now.Add(-31*time.Second)) {
+		t.Fatalf("expected battery to be due after 30s")
+	}
+
+	req.Options.Intervals = map[string]time.Duration{"bat": 5 * time.Second}
+	if !collectorDue(bat, req, now.Add(-10*time.Second)) {
+		t.Fatalf("expected configured interval to override the default")
+	}
+
+	cpu := &collectorFuncs{name: "cpu"}
+	if !collectorDue(cpu, Request{Now: now}, now) {
+		t.Fatalf("expected zero-interval collector to be due on every tick")
+	}
+	req.Options.Intervals = map[string]time.Duration{"cpu": 5 * time.Second}
+	if collectorDue(cpu, req, now.Add(-time.Second)) {
+		t.Fatalf("expected configured interval to override an every-tick collector")
+	}
+	if !collectorDue(cpu, req, now.Add(-6*time.Second)) {
+		t.Fatalf("expected overridden collector to be due after its interval")
+	}
+}
+
 func TestCollectionStatusStaleMetrics(t *testing.T) {
 	status := CollectionStatus{
-		CPU:       MetricStatus{Stale: true},
-		Processes: MetricStatus{Stale: true},
+		"proc": MetricStatus{Stale: true},
+		"cpu":  MetricStatus{Stale: true},
+		"mem":  MetricStatus{},
 	}
 
 	stale := status.StaleMetrics()
@@ -38,3 +79,70 @@ func TestCollectionStatusStaleMetrics(t *testing.T) {
 		t.Fatalf("expected HasStale to return true")
 	}
 }
+
+func TestCollectionStatusUnregisteredNames(t *testing.T) {
+	status := CollectionStatus{
+		"zz-custom": MetricStatus{Stale: true},
+		"cpu":       MetricStatus{Stale: true},
+	}
+
+	stale := status.StaleMetrics()
+	if len(stale) != 2 || stale[0] != "cpu" || stale[1] != "zz-custom" {
+		t.Fatalf("expected registered names before unregistered ones: %#v", stale)
+	}
+	if (CollectionStatus{}).HasStale() {
+		t.Fatalf("expected empty status to have no stale metrics")
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected duplicate registration to panic")
+		}
+	}()
+	Register(&collectorFuncs{name: "cpu"})
+}
+
+func TestBuiltinCollectorsRegistered(t *testing.T) {
+	want := map[string]bool{
+		"cpu": true, "mem": true, "load": true, "proc": true, "gpu": true,
+		"temp": true, "net": true, "disk": true, "bat": true, "psi": true,
+	}
+	for _, c := range Collectors() {
+		delete(want, c.Name())
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing built-in collectors: %v", want)
+	}
+
+	opts := CollectOptions{SkipGPU: true, SkipTemp: true}
+	for _, c := range Collectors() {
+		switch c.Name() {
+		case "gpu", "temp":
+			if c.Enabled(opts) {
+				t.Errorf("expected %s to be disabled by options", c.Name())
+			}
+		default:
+			if !c.Enabled(opts) {
+				t.Errorf("expected %s to be enabled", c.Name())
+			}
+		}
+	}
+}
+
+func TestCollect_ReusesCollectorsNotDue(t *testing.T) {
+	opts := CollectOptions{SkipGPU: true, SkipTemp: true}
+	first := Collect(context.Background(), SortByCPU, 0, time.Hour, Snapshot{}, opts)
+	// cpu, mem, net and disk run concurrently while the collectors that
+	// are not due yet (proc, load, bat, psi) reuse first's values.
+	second := Collect(context.Background(), SortByCPU, 0, time.Hour, first, opts)
+	for _, name := range []string{"proc", "load"} {
+		if !second.SampledAt[name].Equal(first.SampledAt[name]) {
+			t.Errorf("expected %s to be reused, sampled at %v then %v", name, first.SampledAt[name], second.SampledAt[name])
+		}
+	}
+	if len(second.Processes) != len(first.Processes) {
+		t.Errorf("expected the previous process table, got %d processes instead of %d", len(second.Processes), len(first.Processes))
+	}
+}
@@ -2,28 +2,185 @@ package metrics
 
 import (
 	"context"
+	"runtime"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/cpu"
 )
 
-func CollectCPU(ctx context.Context, interval time.Duration) (CPUStats, error) {
-	perCore, err := cpu.PercentWithContext(ctx, interval, true)
+// CPUPrimeWindow is how long after PrimeCPU the first snapshot should be
+// collected for its CPU percentages to span a few scheduler ticks.
+const CPUPrimeWindow = 100 * time.Millisecond
+
+func init() {
+	Register(&collectorFuncs{
+		name: "cpu",
+		collect: func(ctx context.Context, req Request) (func(*Snapshot), error) {
+			counters, err := cpu.TimesWithContext(ctx, true)
+			if err != nil {
+				return nil, err
+			}
+			c, baseline := nextCPU(req.Previous, counters)
+			clocksAt := req.Previous.cpuClocksAt
+			clocks := req.Now.Sub(clocksAt) >= cpuClockEvery
+			if clocks {
+				clocksAt = req.Now
+			}
+			c.Cores = readCPUCores("/sys", "/proc/cpuinfo", req.Previous.CPU.Cores, len(c.PerCore), clocks)
+			return func(s *Snapshot) {
+				s.CPU = c
+				s.cpuTimes = baseline
+				s.cpuClocksAt = clocksAt
+			}, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if !previous.CollectedAt.IsZero() {
+				snap.CPU = previous.CPU
+			}
+			snap.cpuTimes = previous.cpuTimes
+			snap.cpuClocksAt = previous.cpuClocksAt
+		},
+	})
+}
+
+// nextCPU derives CPU usage from a new reading of the per-core counters
+// against the previous snapshot's, and returns the reading to keep as
+// the next baseline. When no core has ticked since the previous reading
+// it keeps the previous usage and baseline, so the next sample covers a
+// longer window.
+func nextCPU(previous Snapshot, counters []cpu.TimesStat) (CPUStats, []cpu.TimesStat) {
+	prev := previous.cpuTimes
+	if len(prev) == len(counters) && !cpuTicked(prev, counters) {
+		if len(previous.CPU.PerCore) == len(counters) {
+			return previous.CPU, prev
+		}
+		prev = nil // only a fresh baseline: fall back to since boot
+	}
+	return cpuStats(prev, counters), counters
+}
+
+// PrimeCPU returns a snapshot holding only a reading of the CPU time
+// counters. Passed to Collect as the previous snapshot, it is the
+// baseline of the first snapshot's CPU percentages, which otherwise
+// average over the time since boot.
+func PrimeCPU(ctx context.Context) Snapshot {
+	counters, err := cpu.TimesWithContext(ctx, true)
+	if err != nil {
+		return Snapshot{}
+	}
+	return Snapshot{cpuTimes: counters}
+}
+
+// CollectCPU returns CPU usage since boot. Collect reports usage since
+// the previous snapshot instead.
+func CollectCPU(ctx context.Context) (CPUStats, error) {
+	counters, err := cpu.TimesWithContext(ctx, true)
 	if err != nil {
 		return CPUStats{}, err
 	}
+	return cpuStats(nil, counters), nil
+}
 
-	var sum float64
-	for _, v := range perCore {
-		sum += v
+// cpuStats derives CPU usage between two readings of the cumulative
+// per-core times. Busy percentages count everything but idle and I/O
+// wait, as cpu.Percent does.
+func cpuStats(prev, cur []cpu.TimesStat) CPUStats {
+	var c CPUStats
+	c.PerCoreTimes, c.Times = cpuBreakdown(prev, cur)
+	if len(c.PerCoreTimes) == 0 {
+		return CPUStats{}
 	}
-	total := 0.0
-	if len(perCore) > 0 {
-		total = sum / float64(len(perCore))
+	c.PerCore = make([]float64, len(c.PerCoreTimes))
+	for i, t := range c.PerCoreTimes {
+		c.PerCore[i] = t.Busy()
+	}
+	c.Total = c.Times.Busy()
+	return c
+}
+
+// cpuTicked reports whether any core's counters advanced between two
+// readings.
+func cpuTicked(prev, cur []cpu.TimesStat) bool {
+	for i := range cur {
+		if cur[i].Total() != prev[i].Total() {
+			return true
+		}
 	}
+	return false
+}
+
+// cpuBreakdown returns each core's CPU time breakdown between two
+// readings of cumulative per-core times, and that of all cores together.
+// Without a matching previous reading it covers the time since boot.
+func cpuBreakdown(prev, cur []cpu.TimesStat) ([]CPUTimes, CPUTimes) {
+	if len(cur) == 0 {
+		return nil, CPUTimes{}
+	}
+	if len(prev) != len(cur) {
+		prev = make([]cpu.TimesStat, len(cur))
+	}
+	perCore := make([]CPUTimes, len(cur))
+	var sumPrev, sumCur cpu.TimesStat
+	for i := range cur {
+		perCore[i] = timesPercent(prev[i], cur[i])
+		addTimes(&sumPrev, prev[i])
+		addTimes(&sumCur, cur[i])
+	}
+	return perCore, timesPercent(sumPrev, sumCur)
+}
+
+// timesPercent returns the share of each CPU state in the time between
+// two cumulative readings.
+func timesPercent(prev, cur cpu.TimesStat) CPUTimes {
+	d := cpu.TimesStat{
+		User:      cur.User - prev.User,
+		Nice:      cur.Nice - prev.Nice,
+		System:    cur.System - prev.System,
+		Idle:      cur.Idle - prev.Idle,
+		Iowait:    cur.Iowait - prev.Iowait,
+		Irq:       cur.Irq - prev.Irq,
+		Softirq:   cur.Softirq - prev.Softirq,
+		Steal:     cur.Steal - prev.Steal,
+		Guest:     cur.Guest - prev.Guest,
+		GuestNice: cur.GuestNice - prev.GuestNice,
+	}
+	if runtime.GOOS == "linux" {
+		// Linux counts guest time in user and nice as well.
+		d.User -= d.Guest
+		d.Nice -= d.GuestNice
+	}
+	t := CPUTimes{
+		User:    max(d.User, 0),
+		Nice:    max(d.Nice, 0),
+		System:  max(d.System, 0),
+		Idle:    max(d.Idle, 0),
+		IOWait:  max(d.Iowait, 0),
+		IRQ:     max(d.Irq, 0),
+		SoftIRQ: max(d.Softirq, 0),
+		Steal:   max(d.Steal, 0),
+		Guest:   max(d.Guest+d.GuestNice, 0),
+	}
+	total := t.Busy() + t.Idle + t.IOWait
+	if total <= 0 {
+		return CPUTimes{}
+	}
+	scale := 100 / total
+	return CPUTimes{
+		User: t.User * scale, Nice: t.Nice * scale, System: t.System * scale,
+		Idle: t.Idle * scale, IOWait: t.IOWait * scale, IRQ: t.IRQ * scale,
+		SoftIRQ: t.SoftIRQ * scale, Steal: t.Steal * scale, Guest: t.Guest * scale,
+	}
+}
 
-	return CPUStats{
-		PerCore: perCore,
-		Total:   total,
-	}, nil
+func addTimes(sum *cpu.TimesStat, t cpu.TimesStat) {
+	sum.User += t.User
+	sum.Nice += t.Nice
+	sum.System += t.System
+	sum.Idle += t.Idle
+	sum.Iowait += t.Iowait
+	sum.Irq += t.Irq
+	sum.Softirq += t.Softirq
+	sum.Steal += t.Steal
+	sum.Guest += t.Guest
+	sum.GuestNice += t.GuestNice
 }
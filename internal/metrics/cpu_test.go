@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+)
+
+func TestCPUBreakdown(t *testing.T) {
+	prev := []cpu.TimesStat{
+		{User: 100, System: 50, Idle: 1000},
+		{User: 10, Idle: 2000, Steal: 5},
+	}
+	cur := []cpu.TimesStat{
+		{User: 106, System: 52, Idle: 1001, Iowait: 1}, // 10s: 60% user, 20% system
+		{User: 12, Idle: 2004, Steal: 9, Softirq: 0.5}, // 10.5s
+	}
+	perCore, total := cpuBreakdown(prev, cur)
+	if len(perCore) != 2 {
+		t.Fatalf("expected 2 cores, got %d", len(perCore))
+	}
+	near := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 0.01 {
+			t.Errorf("%s = %.3f, want %.3f", name, got, want)
+		}
+	}
+	near("core0 user", perCore[0].User, 60)
+	near("core0 system", perCore[0].System, 20)
+	near("core0 iowait", perCore[0].IOWait, 10)
+	near("core0 busy", perCore[0].Busy(), 80)
+	near("core1 steal", perCore[1].Steal, 400/10.5)
+	near("core1 softirq", perCore[1].SoftIRQ, 50/10.5)
+	near("total user", total.User, 800/20.5)
+	near("total steal", total.Steal, 400/20.5)
+	near("total", total.Busy()+total.Idle+total.IOWait, 100)
+
+	// A changed core count restarts from boot rather than mixing cores.
+	perCore, _ = cpuBreakdown(prev[:1], cur)
+	near("since boot user", perCore[1].User, 1200/(12+2004+9+0.5))
+
+	if perCore, total = cpuBreakdown(prev, nil); perCore != nil || total != (CPUTimes{}) {
+		t.Errorf("expected no breakdown without times, got %v %v", perCore, total)
+	}
+}
+
+func TestCPUBreakdown_Guest(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("guest time is part of user time on Linux only")
+	}
+	_, total := cpuBreakdown(
+		[]cpu.TimesStat{{}},
+		[]cpu.TimesStat{{User: 5, Guest: 3, Nice: 2, GuestNice: 2, Idle: 3}},
+	)
+	if math.Abs(total.User-20) > 0.01 || math.Abs(total.Guest-50) > 0.01 || total.Nice != 0 {
+		t.Errorf("unexpected guest split %+v", total)
+	}
+}
+
+func TestNextCPU(t *testing.T) {
+	boot := []cpu.TimesStat{{User: 10, Idle: 90}, {User: 50, Idle: 50}}
+
+	// A fresh baseline that has not ticked yet: usage since boot.
+	c, baseline := nextCPU(Snapshot{cpuTimes: boot}, boot)
+	if c.PerCore[0] != 10 || c.PerCore[1] != 50 || c.Total != 30 || len(baseline) != 2 {
+		t.Errorf("expected usage since boot, got %+v", c)
+	}
+
+	// A primed baseline: usage over the window since it was read.
+	later := []cpu.TimesStat{{User: 10.05, Idle: 90.05}, {User: 50.1, Idle: 50}}
+	c, baseline = nextCPU(Snapshot{cpuTimes: boot}, later)
+	if math.Abs(c.PerCore[0]-50) > 0.01 || math.Abs(c.PerCore[1]-100) > 0.01 || math.Abs(c.Total-75) > 0.01 {
+		t.Errorf("expected usage over the window, got %+v", c)
+	}
+	if &baseline[0] != &later[0] {
+		t.Error("expected the new reading to become the baseline")
+	}
+
+	// No tick since: previous usage and baseline are kept.
+	prev := Snapshot{CPU: c, cpuTimes: later}
+	c, baseline = nextCPU(prev, []cpu.TimesStat{later[0], later[1]})
+	if c.Total != prev.CPU.Total || &baseline[0] != &later[0] {
+		t.Errorf("expected previous usage and baseline kept, got %+v", c)
+	}
+
+	if c, _ := nextCPU(Snapshot{}, nil); c.PerCore != nil || c.Total != 0 {
+		t.Errorf("expected no usage without counters, got %+v", c)
+	}
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youhide/hideTop/internal/cpulist"
+)
+
+// Core types on hybrid CPUs.
+const (
+	CoreTypePerformance = "P"
+	CoreTypeEfficiency  = "E"
+)
+
+// CPUCore describes a logical CPU: where it sits in the package / core /
+// SMT topology, its core type on hybrid CPUs, and its clock. Read from
+// sysfs on Linux; what the system does not expose stays zero.
+type CPUCore struct {
+	CPU      int    // logical CPU number
+	Package  int    // physical package (socket) ID
+	Core     int    // core ID within the package
+	Threads  int    // logical CPUs sharing the core, this one included
+	Type     string // CoreTypePerformance or CoreTypeEfficiency; "" unless hybrid
+	MHz      float64
+	MaxMHz   float64
+	Governor string // cpufreq scaling governor
+}
+
+// cpuClockEvery is how often readCPUCores re-reads the clocks, which
+// costs two sysfs reads per CPU (or a /proc/cpuinfo parse) for values
+// that are only shown rounded.
+const cpuClockEvery = 2 * time.Second
+
+// readCPUCores reads the topology and clocks of the online CPUs from
+// sysfs under sys, falling back to cpuinfo for clocks. The topology of
+// prev is reused when it covers the same CPUs, since only hotplug
+// changes it, and so are its clocks unless clocks is set. It returns nil
+// off Linux, or when the online CPUs do not line up with the n per-core
+// usage readings.
+func readCPUCores(sys, cpuinfo string, prev []CPUCore, n int, clocks bool) []CPUCore {
+	if runtime.GOOS != "linux" || n == 0 {
+		return nil
+	}
+	online, err := cpulist.Parse(readSysString(filepath.Join(sys, "devices/system/cpu/online")))
+	if err != nil || len(online) != n {
+		return nil
+	}
+
+	cores := make([]CPUCore, n)
+	reuse := len(prev) == n
+	for i, cpu := range online {
+		if reuse && prev[i].CPU != cpu {
+			reuse = false
+		}
+		cores[i].CPU = cpu
+	}
+	if reuse {
+		copy(cores, prev)
+	} else {
+		readTopology(sys, cores)
+	}
+	if clocks || !reuse {
+		readClocks(sys, cpuinfo, cores)
+	}
+	return cores
+}
+
+// readClocks fills in the current clock and governor of cores.
+func readClocks(sys, cpuinfo string, cores []CPUCore) {
+	var fallback map[int]float64
+	for i := range cores {
+		dir := filepath.Join(sys, "devices/system/cpu", "cpu"+strconv.Itoa(cores[i].CPU), "cpufreq")
+		if khz, err := strconv.ParseFloat(readSysString(filepath.Join(dir, "scaling_cur_freq")), 64); err == nil {
+			cores[i].MHz = khz / 1000
+		} else {
+			if fallback == nil {
+				fallback = readCPUInfoMHz(cpuinfo)
+			}
+			cores[i].MHz = fallback[cores[i].CPU]
+		}
+		cores[i].Governor = readSysString(filepath.Join(dir, "scaling_governor"))
+	}
+}
+
+// readTopology fills in the fields of cores that do not change without
+// hotplug.
+func readTopology(sys string, cores []CPUCore) {
+	capacity := make([]int, len(cores))
+	for i := range cores {
+		dir := filepath.Join(sys, "devices/system/cpu", "cpu"+strconv.Itoa(cores[i].CPU))
+		cores[i].Package = readSysInt(filepath.Join(dir, "topology/physical_package_id"))
+		cores[i].Core = readSysInt(filepath.Join(dir, "topology/core_id"))
+		cores[i].Threads = 1
+		if siblings, err := cpulist.Parse(readSysString(filepath.Join(dir, "topology/thread_siblings_list"))); err == nil {
+			cores[i].Threads = len(siblings)
+		}
+		if khz, err := strconv.ParseFloat(readSysString(filepath.Join(dir, "cpufreq/cpuinfo_max_freq")), 64); err == nil {
+			cores[i].MaxMHz = khz / 1000
+		}
+		capacity[i] = readSysInt(filepath.Join(dir, "cpu_capacity"))
+	}
+
+	// Intel hybrid CPUs expose a PMU per core type listing its CPUs;
+	// on ARM big.LITTLE, and newer kernels on x86, the scheduler's
+	// capacity tells the big cores from the little ones.
+	pcores, perr := cpulist.Parse(readSysString(filepath.Join(sys, "devices/cpu_core/cpus")))
+	ecores, eerr := cpulist.Parse(readSysString(filepath.Join(sys, "devices/cpu_atom/cpus")))
+	if perr == nil && eerr == nil {
+		for i := range cores {
+			switch {
+			case slices.Contains(pcores, cores[i].CPU):
+				cores[i].Type = CoreTypePerformance
+			case slices.Contains(ecores, cores[i].CPU):
+				cores[i].Type = CoreTypeEfficiency
+			}
+		}
+		return
+	}
+	top := slices.Max(capacity)
+	if top == 0 || slices.Min(capacity) == top {
+		return
+	}
+	for i := range cores {
+		cores[i].Type = CoreTypeEfficiency
+		if capacity[i] == top {
+			cores[i].Type = CoreTypePerformance
+		}
+	}
+}
+
+// readCPUInfoMHz returns the "cpu MHz" of each processor in a
+// /proc/cpuinfo file, for systems without cpufreq such as most VMs.
+func readCPUInfoMHz(path string) map[int]float64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	mhz := make(map[int]float64)
+	cpu := -1
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "processor":
+			if cpu, err = strconv.Atoi(strings.TrimSpace(val)); err != nil {
+				cpu = -1
+			}
+		case "cpu MHz":
+			if v, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil && cpu >= 0 {
+				mhz[cpu] = v
+			}
+		}
+	}
+	return mhz
+}
+
+// readSysString returns a sysfs file's content without the trailing
+// newline, or "" if it cannot be read.
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysInt returns a sysfs file's integer value, or 0.
+func readSysInt(path string) int {
+	v, _ := strconv.Atoi(readSysString(path))
+	return v
+}
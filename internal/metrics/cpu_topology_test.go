@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeFiles creates files under root from a path → content map.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadCPUCores(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sysfs topology is Linux only")
+	}
+	sys := t.TempDir()
+	cpu := "devices/system/cpu/"
+	writeFiles(t, sys, map[string]string{
+		cpu + "online": "0-2",
+		cpu + "cpu0/topology/physical_package_id":  "0",
+		cpu + "cpu0/topology/core_id":              "0",
+		cpu + "cpu0/topology/thread_siblings_list": "0-1",
+		cpu + "cpu0/cpufreq/scaling_cur_freq":      "4700000",
+		cpu + "cpu0/cpufreq/cpuinfo_max_freq":      "5000000",
+		cpu + "cpu0/cpufreq/scaling_governor":      "powersave",
+		cpu + "cpu1/topology/physical_package_id":  "0",
+		cpu + "cpu1/topology/core_id":              "0",
+		cpu + "cpu1/topology/thread_siblings_list": "0-1",
+		cpu + "cpu1/cpufreq/scaling_cur_freq":      "4600000",
+		cpu + "cpu2/topology/physical_package_id":  "0",
+		cpu + "cpu2/topology/core_id":              "8",
+		cpu + "cpu2/topology/thread_siblings_list": "2",
+		"devices/cpu_core/cpus":                    "0-1",
+		"devices/cpu_atom/cpus":                    "2",
+	})
+	cpuinfo := filepath.Join(sys, "cpuinfo")
+	writeFiles(t, sys, map[string]string{"cpuinfo": "processor\t: 2\ncpu MHz\t\t: 800.500\n"})
+
+	cores := readCPUCores(sys, cpuinfo, nil, 3, true)
+	if len(cores) != 3 {
+		t.Fatalf("expected 3 cores, got %+v", cores)
+	}
+	if c := cores[0]; c.Threads != 2 || c.Type != CoreTypePerformance || c.MHz != 4700 || c.MaxMHz != 5000 || c.Governor != "powersave" {
+		t.Errorf("unexpected cpu0 %+v", c)
+	}
+	if c := cores[2]; c.Core != 8 || c.Threads != 1 || c.Type != CoreTypeEfficiency || c.MHz != 800.5 {
+		t.Errorf("unexpected cpu2 %+v", c)
+	}
+
+	// The topology is carried over; clocks are too until they are due.
+	prev := cores
+	prev[2].Type = "carried"
+	writeFiles(t, sys, map[string]string{cpu + "cpu0/cpufreq/scaling_cur_freq": "800000"})
+	cores = readCPUCores(sys, cpuinfo, prev, 3, false)
+	if cores[2].Type != "carried" || cores[0].MHz != 4700 || cores[0].Governor != "powersave" {
+		t.Errorf("expected carried topology and clock, got %+v", cores)
+	}
+	cores = readCPUCores(sys, cpuinfo, prev, 3, true)
+	if cores[2].Type != "carried" || cores[0].MHz != 800 {
+		t.Errorf("expected carried topology and fresh clock, got %+v", cores)
+	}
+
+	// Clocks are read with a fresh topology even when not due.
+	if cores := readCPUCores(sys, cpuinfo, nil, 3, false); cores[0].MHz != 800 || cores[2].MHz != 800.5 {
+		t.Errorf("expected clocks with a fresh topology, got %+v", cores)
+	}
+
+	if cores := readCPUCores(sys, cpuinfo, nil, 4, true); cores != nil {
+		t.Errorf("expected nil when the CPU count does not match, got %+v", cores)
+	}
+}
+
+func TestReadCPUCores_Capacity(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sysfs topology is Linux only")
+	}
+	sys := t.TempDir()
+	cpu := "devices/system/cpu/"
+	writeFiles(t, sys, map[string]string{
+		cpu + "online":            "0-1",
+		cpu + "cpu0/cpu_capacity": "446",
+		cpu + "cpu1/cpu_capacity": "1024",
+	})
+	cores := readCPUCores(sys, "", nil, 2, true)
+	if len(cores) != 2 || cores[0].Type != CoreTypeEfficiency || cores[1].Type != CoreTypePerformance {
+		t.Errorf("unexpected core types %+v", cores)
+	}
+
+	writeFiles(t, sys, map[string]string{cpu + "cpu0/cpu_capacity": "1024"})
+	if cores := readCPUCores(sys, "", nil, 2, true); cores[0].Type != "" || cores[1].Type != "" {
+		t.Errorf("expected no core types on a uniform CPU, got %+v", cores)
+	}
+}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 )
 
 func TestComputeNetworkDelta(t *testing.T) {
@@ -171,3 +172,33 @@ func TestComputeDiskDelta_CounterWrap(t *testing.T) {
 		t.Errorf("expected device ReadSec=0 on counter wrap, got %f", delta.Devices[0].ReadSec)
 	}
 }
+
+func TestIORates(t *testing.T) {
+	t0 := time.Now()
+	prev := ioSample{read: 1000, write: 500, at: t0}
+	cur := ioSample{read: 5000, write: 2500, at: t0.Add(2 * time.Second)}
+
+	read, write := ioRates(prev, cur)
+	if read != 2000 || write != 1000 {
+		t.Errorf("expected 2000/1000 B/s, got %f/%f", read, write)
+	}
+	if read, write := ioRates(ioSample{}, cur); read != 0 || write != 0 {
+		t.Errorf("expected zero rates without a previous sample, got %f/%f", read, write)
+	}
+	// A reused PID starts its counters again from zero.
+	if read, write := ioRates(cur, ioSample{read: 10, write: 10, at: t0.Add(4 * time.Second)}); read != 0 || write != 0 {
+		t.Errorf("expected zero rates when counters go backwards, got %f/%f", read, write)
+	}
+}
+
+func TestSortProcesses_IO(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, ReadRate: 10},
+		{PID: 2, ReadRate: 5, WriteRate: 100},
+		{PID: 3},
+	}
+	SortProcesses(procs, SortByIO, false)
+	if procs[0].PID != 2 || procs[1].PID != 1 || procs[2].PID != 3 {
+		t.Errorf("unexpected I/O order: %d %d %d", procs[0].PID, procs[1].PID, procs[2].PID)
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Diagnostics lists the processes behind the usual hung-box symptoms:
+// zombies, processes stuck in uninterruptible (D) sleep, and processes
+// whose parent exited so they were reparented to init or a subreaper.
+// It is derived from successive snapshots with Diagnose.
+type Diagnostics struct {
+	Zombies    []ProcessProblem // with Parent set
+	Stuck      []ProcessProblem // in D state
+	Reparented []ProcessProblem // with Parent and FormerParent set
+
+	seen map[int32]diagEntry
+}
+
+// ProcessProblem is one process listed by Diagnostics.
+type ProcessProblem struct {
+	Process      ProcessInfo
+	Parent       ProcessInfo // zero when the parent is not in the table
+	Since        time.Time   // when the state or reparenting was first seen
+	FormerParent ProcessInfo // Reparented only: the parent before
+}
+
+// diagEntry is what Diagnose remembers about a process between samples.
+type diagEntry struct {
+	createTime int64
+	parent     ProcessInfo
+	state      string
+	stateSince time.Time
+	former     ProcessInfo // parent before reparenting; PID 0 if never
+	reparented time.Time
+}
+
+// Diagnose derives the diagnostics of procs, sampled at now, from the
+// previous result. Durations count from the first sample a state was
+// seen in, so a process already stuck at startup shows a lower bound.
+func Diagnose(procs []ProcessInfo, prev Diagnostics, now time.Time) Diagnostics {
+	byPID := make(map[int32]ProcessInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	d := Diagnostics{seen: make(map[int32]diagEntry, len(procs))}
+	for _, p := range procs {
+		e, known := prev.seen[p.PID]
+		if !known || e.createTime != p.CreateTime {
+			e = diagEntry{createTime: p.CreateTime, state: p.State, stateSince: now}
+		} else if e.parent.PID != p.PPID && e.parent.PID != 0 {
+			e.former = e.parent
+			e.reparented = now
+		}
+		if e.state != p.State {
+			e.state, e.stateSince = p.State, now
+		}
+		parent := byPID[p.PPID]
+		if p.PPID == 0 {
+			parent = ProcessInfo{}
+		}
+		e.parent = ProcessInfo{PID: p.PPID, Name: parent.Name}
+		d.seen[p.PID] = e
+
+		switch StateLetter(p.State) {
+		case "Z":
+			d.Zombies = append(d.Zombies, ProcessProblem{Process: p, Parent: parent, Since: e.stateSince})
+		case "D":
+			d.Stuck = append(d.Stuck, ProcessProblem{Process: p, Parent: parent, Since: e.stateSince})
+		}
+		if e.former.PID != 0 {
+			d.Reparented = append(d.Reparented, ProcessProblem{
+				Process: p, Parent: parent, Since: e.reparented, FormerParent: e.former,
+			})
+		}
+	}
+	for _, list := range [][]ProcessProblem{d.Zombies, d.Stuck, d.Reparented} {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].Since.Equal(list[j].Since) {
+				return list[i].Since.Before(list[j].Since) // longest first
+			}
+			return list[i].Process.PID < list[j].Process.PID
+		})
+	}
+	return d
+}
+
+// Count returns the number of listed processes.
+func (d Diagnostics) Count() int {
+	return len(d.Zombies) + len(d.Stuck) + len(d.Reparented)
+}
+
+// At returns the i-th listed process, counting through Zombies, Stuck
+// and Reparented in that order.
+func (d Diagnostics) At(i int) (ProcessProblem, bool) {
+	for _, list := range [][]ProcessProblem{d.Zombies, d.Stuck, d.Reparented} {
+		if i < len(list) {
+			return list[i], i >= 0
+		}
+		i -= len(list)
+	}
+	return ProcessProblem{}, false
+}
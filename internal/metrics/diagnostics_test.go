@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiagnose(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	sample := func(procs ...ProcessInfo) []ProcessInfo { return procs }
+	shell := ProcessInfo{PID: 50, PPID: 1, Name: "bash", State: "sleeping", CreateTime: 1}
+	worker := ProcessInfo{PID: 60, PPID: 50, Name: "make", State: "blocked", CreateTime: 2}
+	zombie := ProcessInfo{PID: 61, PPID: 50, Name: "cc", State: "zombie", CreateTime: 3}
+
+	d := Diagnose(sample(shell, worker, zombie), Diagnostics{}, t0)
+	if len(d.Zombies) != 1 || d.Zombies[0].Parent.Name != "bash" {
+		t.Fatalf("expected zombie with parent bash, got %+v", d.Zombies)
+	}
+	if len(d.Stuck) != 1 || !d.Stuck[0].Since.Equal(t0) {
+		t.Fatalf("expected make stuck since t0, got %+v", d.Stuck)
+	}
+
+	// The D state persists; its start must not move.
+	d = Diagnose(sample(shell, worker, zombie), d, t0.Add(5*time.Second))
+	if got := d.Stuck[0].Since; !got.Equal(t0) {
+		t.Fatalf("stuck since moved to %v", got)
+	}
+
+	// The shell exits: its children move to init.
+	worker.PPID, worker.State = 1, "running"
+	zombie.PPID = 1
+	t2 := t0.Add(10 * time.Second)
+	d = Diagnose(sample(worker, zombie), d, t2)
+	if len(d.Stuck) != 0 {
+		t.Fatalf("make left D state, got %+v", d.Stuck)
+	}
+	if len(d.Reparented) != 2 || d.Reparented[0].FormerParent.Name != "bash" || !d.Reparented[0].Since.Equal(t2) {
+		t.Fatalf("expected 2 processes reparented from bash, got %+v", d.Reparented)
+	}
+	if d.Count() != 3 {
+		t.Fatalf("Count = %d, want 3", d.Count())
+	}
+	if p, ok := d.At(0); !ok || p.Process.PID != 61 {
+		t.Fatalf("At(0) = %+v, want the zombie", p)
+	}
+	if p, ok := d.At(2); !ok || p.Process.PID != 61 || p.FormerParent.PID != 50 {
+		t.Fatalf("At(2) = %+v, want the reparented zombie", p)
+	}
+	if _, ok := d.At(3); ok {
+		t.Fatalf("At(3) should be out of range")
+	}
+
+	// A reused PID starts over.
+	d = Diagnose(sample(ProcessInfo{PID: 60, PPID: 1, Name: "other", State: "sleeping", CreateTime: 9}), d, t2)
+	if d.Count() != 0 {
+		t.Fatalf("reused PID kept its history: %+v", d)
+	}
+}
@@ -40,6 +40,24 @@ type DiskDeviceDelta struct {
 	WriteSec float64
 }
 
+func init() {
+	Register(&collectorFuncs{
+		name: "disk",
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			d, err := CollectDisk(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Disk = d }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if previous.Disk.Available {
+				snap.Disk = previous.Disk
+			}
+		},
+	})
+}
+
 // CollectDisk gathers disk I/O counters and root filesystem usage.
 func CollectDisk(ctx context.Context) (DiskStats, error) {
 	stats := DiskStats{}
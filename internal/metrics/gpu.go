@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics/gpu"
+)
+
+func init() {
+	Register(&collectorFuncs{
+		name:     "gpu",
+		every:    2 * time.Second, // nvidia-smi and ioreg are process execs
+		enabled:  func(opts CollectOptions) bool { return !opts.SkipGPU },
+		collect:  collectGPU,
+		fallback: fallbackGPU,
+	})
+}
+
+// collectGPU wraps gpu.Collect. A GPU that was available on the previous
+// tick but not on this one is reported as a failure so the panel keeps
+// its last values instead of disappearing.
+func collectGPU(ctx context.Context, req Request) (func(*Snapshot), error) {
+	g := gpu.Collect(ctx, 0) // cpuTotal not needed for raw GPU metrics
+	if g.Available {
+		return func(s *Snapshot) { s.GPU = &g }, nil
+	}
+	if req.Previous.GPU != nil && req.Previous.GPU.Available {
+		return nil, errors.New("collector unavailable")
+	}
+	return nil, nil
+}
+
+func fallbackGPU(snap *Snapshot, previous Snapshot) {
+	if previous.GPU != nil && previous.GPU.Available {
+		g := *previous.GPU
+		snap.GPU = &g
+	}
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// GroupBy selects how the process panel aggregates processes.
+type GroupBy int
+
+const (
+	GroupNone GroupBy = iota
+	GroupContainer
+	GroupUnit
+	GroupUser
+)
+
+// Keys shown for processes outside any container or systemd unit, and
+// for processes whose owner could not be read.
+const (
+	GroupKeyHost    = "(host)"
+	GroupKeyNone    = "(none)"
+	GroupKeyUnknown = "(unknown)"
+)
+
+func (g GroupBy) String() string {
+	switch g {
+	case GroupContainer:
+		return "container"
+	case GroupUnit:
+		return "unit"
+	case GroupUser:
+		return "user"
+	default:
+		return "none"
+	}
+}
+
+// Next returns the grouping mode after g, wrapping to GroupNone.
+func (g GroupBy) Next() GroupBy {
+	if g >= GroupUser {
+		return GroupNone
+	}
+	return g + 1
+}
+
+// GroupKey returns the group p belongs to under g.
+func GroupKey(p ProcessInfo, g GroupBy) string {
+	switch g {
+	case GroupContainer:
+		if p.Container != "" {
+			return p.Container
+		}
+		return GroupKeyHost
+	case GroupUnit:
+		if p.Unit != "" {
+			return p.Unit
+		}
+		return GroupKeyNone
+	case GroupUser:
+		if p.User != "" {
+			return p.User
+		}
+		return GroupKeyUnknown
+	default:
+		return ""
+	}
+}
+
+// ProcessGroup aggregates the processes sharing a group key.
+type ProcessGroup struct {
+	Key        string
+	Count      int
+	Threads    int32
+	CPUPercent float64
+	MemPercent float64
+	ReadRate   float64
+	WriteRate  float64
+	RSS        uint64
+}
+
+// GroupProcesses aggregates procs under g, sorted by sortBy. Fields
+// with a per-group total (CPU, memory, I/O, threads, RSS) sort by it,
+// and PID by process count, largest first like the totals; the others
+// sort by group key. reverse flips the default direction.
+func GroupProcesses(procs []ProcessInfo, g GroupBy, sortBy SortField, reverse bool) []ProcessGroup {
+	idx := make(map[string]int)
+	var groups []ProcessGroup
+	for _, p := range procs {
+		key := GroupKey(p, g)
+		i, ok := idx[key]
+		if !ok {
+			i = len(groups)
+			idx[key] = i
+			groups = append(groups, ProcessGroup{Key: key})
+		}
+		groups[i].Count++
+		groups[i].Threads += p.NumThreads
+		groups[i].CPUPercent += p.CPUPercent
+		groups[i].MemPercent += float64(p.MemPercent)
+		groups[i].ReadRate += p.ReadRate
+		groups[i].WriteRate += p.WriteRate
+		groups[i].RSS += p.RSS
+	}
+	field := sortBy
+	switch sortBy {
+	case SortByCPU, SortByMem, SortByIO, SortByThreads, SortByRSS:
+	case SortByPID:
+		reverse = !reverse // PIDs ascend, counts descend
+	default:
+		field = SortByName // no per-group total: order by key
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return lessKey(groups[i].sortKey(field), groups[j].sortKey(field), field, reverse)
+	})
+	return groups
+}
+
+func (g ProcessGroup) sortKey(f SortField) sortKey {
+	switch f {
+	case SortByCPU:
+		return sortKey{num: g.CPUPercent}
+	case SortByMem:
+		return sortKey{num: g.MemPercent}
+	case SortByIO:
+		return sortKey{num: g.ReadRate + g.WriteRate}
+	case SortByThreads:
+		return sortKey{num: float64(g.Threads)}
+	case SortByRSS:
+		return sortKey{num: float64(g.RSS)}
+	case SortByPID:
+		return sortKey{num: float64(g.Count)}
+	default:
+		return sortKey{text: strings.ToLower(g.Key)}
+	}
+}
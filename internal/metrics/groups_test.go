@@ -0,0 +1,58 @@
+package metrics
+
+import "testing"
+
+func TestGroupProcesses(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, CPUPercent: 1, MemPercent: 5},
+		{PID: 2, Container: "docker/aaa", CPUPercent: 30, MemPercent: 1, NumThreads: 2},
+		{PID: 3, Container: "docker/aaa", CPUPercent: 20, MemPercent: 1, NumThreads: 3},
+		{PID: 4, Container: "docker/bbb", CPUPercent: 40, MemPercent: 2},
+	}
+	groups := GroupProcesses(procs, GroupContainer, SortByCPU, false)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", groups)
+	}
+	if g := groups[0]; g.Key != "docker/aaa" || g.Count != 2 || g.CPUPercent != 50 || g.Threads != 5 {
+		t.Errorf("unexpected top group %+v", g)
+	}
+	if groups[2].Key != GroupKeyHost {
+		t.Errorf("expected host group last, got %q", groups[2].Key)
+	}
+	groups = GroupProcesses(procs, GroupContainer, SortByMem, false)
+	if groups[0].Key != GroupKeyHost {
+		t.Errorf("expected host group first by memory, got %q", groups[0].Key)
+	}
+}
+
+func TestGroupProcesses_ByUser(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, User: "root", CPUPercent: 1, RSS: 10},
+		{PID: 2, User: "alice", CPUPercent: 80, RSS: 100, NumThreads: 4},
+		{PID: 3, User: "alice", CPUPercent: 70, RSS: 200, NumThreads: 8},
+		{PID: 4, User: "bob", CPUPercent: 5, RSS: 5000},
+		{PID: 5, CPUPercent: 0},
+	}
+	groups := GroupProcesses(procs, GroupUser, SortByCPU, false)
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %+v", groups)
+	}
+	if g := groups[0]; g.Key != "alice" || g.Count != 2 || g.CPUPercent != 150 || g.Threads != 12 || g.RSS != 300 {
+		t.Errorf("unexpected top group %+v", g)
+	}
+	if groups[3].Key != GroupKeyUnknown {
+		t.Errorf("expected unknown user last, got %q", groups[3].Key)
+	}
+	if groups = GroupProcesses(procs, GroupUser, SortByRSS, false); groups[0].Key != "bob" {
+		t.Errorf("expected bob first by RSS, got %q", groups[0].Key)
+	}
+	if groups = GroupProcesses(procs, GroupUser, SortByPID, false); groups[0].Key != "alice" {
+		t.Errorf("expected alice first by process count, got %q", groups[0].Key)
+	}
+	if groups = GroupProcesses(procs, GroupUser, SortByPID, true); groups[0].Count != 1 {
+		t.Errorf("expected a single-process group first by reversed count, got %+v", groups[0])
+	}
+	if groups = GroupProcesses(procs, GroupUser, SortByUser, false); groups[0].Key != GroupKeyUnknown || groups[1].Key != "alice" {
+		t.Errorf("expected groups by name, got %+v", groups)
+	}
+}
@@ -0,0 +1,317 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ProcessInspection is an in-depth read of one process for the process
+// detail view. It is too costly to collect for every process, so it is
+// only read on demand. Sections that could not be read (typically another
+// user's process without root) have their error in Errors, keyed by
+// section: "exe", "cwd", "env", "files", "maps", "threads".
+type ProcessInspection struct {
+	Exe      string
+	Cwd      string
+	Environ  []string
+	Files    []OpenFile
+	Maps     []MemoryMap   // grouped by path, largest RSS first
+	Threads  []ThreadInfo  // busiest first
+	Cgroups  []string      // raw /proc/<pid>/cgroup lines
+	Limits   []CgroupFile  // cgroup v2 limits and usage of the process's cgroup
+	Pressure PressureStats // cgroup v2 PSI of the process's cgroup
+	Errors   map[string]string
+
+	at time.Time
+}
+
+// OpenFile is an open file descriptor.
+type OpenFile struct {
+	FD     uint64
+	Path   string // target, e.g. a path, "pipe:[123]" or "socket:[456]"
+	Socket string // for sockets: "tcp 10.0.0.1:22 → 10.0.0.9:51234 ESTABLISHED"
+}
+
+// MemoryMap sums the mappings of one file or anonymous region.
+type MemoryMap struct {
+	Path  string // file path, or "[heap]", "[stack]", "[anon]"
+	Count int    // number of mappings
+	Size  uint64 // bytes
+	RSS   uint64 // bytes
+	Swap  uint64 // bytes
+}
+
+// ThreadInfo is one thread of a process.
+type ThreadInfo struct {
+	TID        int32
+	Name       string
+	CPUTime    float64 // user + system seconds
+	CPUPercent float64 // since the previous inspection; 0 on the first
+}
+
+// CgroupFile is one cgroup interface file and its contents.
+type CgroupFile struct {
+	Name  string
+	Value string
+}
+
+// cgroupFiles are the cgroup v2 files shown for a process's cgroup.
+var cgroupFiles = []string{
+	"memory.current", "memory.max", "memory.high", "memory.swap.current",
+	"cpu.max", "cpu.weight", "pids.current", "pids.max",
+}
+
+// InspectProcess reads pid in depth. prev is the previous inspection of
+// the same process (or the zero value); it is used for per-thread CPU%.
+// It fails only when the process does not exist.
+func InspectProcess(ctx context.Context, pid int32, prev ProcessInspection) (ProcessInspection, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return ProcessInspection{}, err
+	}
+	in := ProcessInspection{Errors: make(map[string]string), at: time.Now()}
+	fail := func(section string, err error) {
+		in.Errors[section] = err.Error()
+	}
+
+	if exe, err := p.ExeWithContext(ctx); err == nil {
+		in.Exe = exe
+	} else {
+		fail("exe", err)
+	}
+	if cwd, err := p.CwdWithContext(ctx); err == nil {
+		in.Cwd = cwd
+	} else {
+		fail("cwd", err)
+	}
+	if env, err := p.EnvironWithContext(ctx); err == nil {
+		in.Environ = env
+	} else {
+		fail("env", err)
+	}
+	if files, err := readOpenFiles(ctx, p); err == nil {
+		in.Files = files
+	} else {
+		fail("files", err)
+	}
+	if maps, err := readMemoryMaps(pid); err == nil {
+		in.Maps = maps
+	} else {
+		fail("maps", err)
+	}
+	if threads, err := readThreads(ctx, p, prev, in.at); err == nil {
+		in.Threads = threads
+	} else {
+		fail("threads", err)
+	}
+	in.Cgroups, in.Limits, in.Pressure = readCgroupInfo(pid)
+	return in, nil
+}
+
+// readOpenFiles lists p's file descriptors, describing sockets with their
+// addresses where the connection table has them.
+func readOpenFiles(ctx context.Context, p *process.Process) ([]OpenFile, error) {
+	fds, err := p.OpenFilesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sockets := make(map[uint64]string)
+	if conns, err := psnet.ConnectionsPidWithContext(ctx, "all", p.Pid); err == nil {
+		for _, c := range conns {
+			sockets[uint64(c.Fd)] = socketLabel(c)
+		}
+	}
+	files := make([]OpenFile, 0, len(fds))
+	for _, f := range fds {
+		files = append(files, OpenFile{FD: f.Fd, Path: f.Path, Socket: sockets[f.Fd]})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].FD < files[j].FD })
+	return files, nil
+}
+
+// socketLabel describes a connection like "tcp6 [::]:22 LISTEN".
+func socketLabel(c psnet.ConnectionStat) string {
+	if c.Family == syscall.AF_UNIX {
+		if c.Laddr.IP != "" {
+			return "unix " + c.Laddr.IP
+		}
+		return "unix"
+	}
+	proto := "tcp"
+	if c.Type == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if c.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	label := proto + " " + hostPort(c.Laddr)
+	if c.Raddr.IP != "" || c.Raddr.Port != 0 {
+		label += " → " + hostPort(c.Raddr)
+	}
+	if c.Status != "" && c.Status != "NONE" {
+		label += " " + c.Status
+	}
+	return label
+}
+
+func hostPort(a psnet.Addr) string {
+	if strings.Contains(a.IP, ":") {
+		return fmt.Sprintf("[%s]:%d", a.IP, a.Port)
+	}
+	return fmt.Sprintf("%s:%d", a.IP, a.Port)
+}
+
+// readMemoryMaps summarises /proc/<pid>/smaps (Linux only).
+func readMemoryMaps(pid int32) ([]MemoryMap, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/smaps")
+	if err != nil {
+		return nil, err
+	}
+	return parseSmaps(string(data)), nil
+}
+
+// parseSmaps groups the mappings of an smaps file by path, largest RSS
+// first. Anonymous mappings are grouped as "[anon]".
+func parseSmaps(data string) []MemoryMap {
+	byPath := make(map[string]*MemoryMap)
+	var cur *MemoryMap
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Header lines start with an address range: "7f..-7f.. r-xp ...".
+		if strings.Contains(fields[0], "-") && !strings.HasSuffix(fields[0], ":") {
+			name := "[anon]"
+			if len(fields) >= 6 {
+				name = strings.Join(fields[5:], " ")
+			}
+			if byPath[name] == nil {
+				byPath[name] = &MemoryMap{Path: name}
+			}
+			cur = byPath[name]
+			cur.Count++
+			continue
+		}
+		if cur == nil || len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "Size:":
+			cur.Size += kb * 1024
+		case "Rss:":
+			cur.RSS += kb * 1024
+		case "Swap:":
+			cur.Swap += kb * 1024
+		}
+	}
+	maps := make([]MemoryMap, 0, len(byPath))
+	for _, m := range byPath {
+		maps = append(maps, *m)
+	}
+	sort.Slice(maps, func(i, j int) bool {
+		if maps[i].RSS != maps[j].RSS {
+			return maps[i].RSS > maps[j].RSS
+		}
+		return maps[i].Path < maps[j].Path
+	})
+	return maps
+}
+
+// readThreads reads p's threads, with CPU% since prev where the thread
+// was already present.
+func readThreads(ctx context.Context, p *process.Process, prev ProcessInspection, now time.Time) ([]ThreadInfo, error) {
+	times, err := p.ThreadsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[int32]float64, len(prev.Threads))
+	for _, t := range prev.Threads {
+		last[t.TID] = t.CPUTime
+	}
+	secs := now.Sub(prev.at).Seconds()
+
+	threads := make([]ThreadInfo, 0, len(times))
+	for tid, t := range times {
+		if t == nil {
+			continue
+		}
+		ti := ThreadInfo{TID: tid, Name: threadName(p.Pid, tid), CPUTime: t.User + t.System}
+		if before, ok := last[tid]; ok && !prev.at.IsZero() && secs > 0 && ti.CPUTime >= before {
+			ti.CPUPercent = 100 * (ti.CPUTime - before) / secs
+		}
+		threads = append(threads, ti)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		if threads[i].CPUPercent != threads[j].CPUPercent {
+			return threads[i].CPUPercent > threads[j].CPUPercent
+		}
+		if threads[i].CPUTime != threads[j].CPUTime {
+			return threads[i].CPUTime > threads[j].CPUTime
+		}
+		return threads[i].TID < threads[j].TID
+	})
+	return threads, nil
+}
+
+// threadName returns a thread's name (Linux only).
+func threadName(pid, tid int32) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/task/" + strconv.Itoa(int(tid)) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readCgroupInfo returns the raw cgroup membership of pid and, on cgroup
+// v2, the limits, usage and pressure of its cgroup (Linux only).
+func readCgroupInfo(pid int32) ([]string, []CgroupFile, PressureStats) {
+	if runtime.GOOS != "linux" {
+		return nil, nil, PressureStats{}
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return nil, nil, PressureStats{}
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	var limits []CgroupFile
+	var pressure PressureStats
+	for _, line := range lines {
+		cg, ok := strings.CutPrefix(line, "0::")
+		if !ok {
+			continue
+		}
+		dir := path.Join("/sys/fs/cgroup", cg)
+		for _, name := range cgroupFiles {
+			v, err := os.ReadFile(path.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			limits = append(limits, CgroupFile{Name: name, Value: strings.TrimSpace(string(v))})
+		}
+		pressure = readCgroupPressure(dir)
+	}
+	return lines, limits, pressure
+}
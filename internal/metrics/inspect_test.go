@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"syscall"
+	"testing"
+
+	psnet "github.com/shirou/gopsutil/v4/net"
+)
+
+func TestParseSmaps(t *testing.T) {
+	smaps := `55d0c0a00000-55d0c0a21000 r-xp 00000000 08:01 1234 /usr/bin/my prog
+Size:                132 kB
+Rss:                 100 kB
+Swap:                  0 kB
+55d0c0c21000-55d0c0c22000 rw-p 00021000 08:01 1234 /usr/bin/my prog
+Size:                  4 kB
+Rss:                   4 kB
+Swap:                  0 kB
+55d0c1000000-55d0c1200000 rw-p 00000000 00:00 0 
+Size:               2048 kB
+Rss:                1024 kB
+Swap:                 16 kB
+VmFlags: rd wr mr mw me ac
+7ffd00000000-7ffd00021000 rw-p 00000000 00:00 0 [stack]
+Size:                132 kB
+Rss:                   8 kB
+`
+	maps := parseSmaps(smaps)
+	if len(maps) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", maps)
+	}
+	if maps[0].Path != "[anon]" || maps[0].RSS != 1024*1024 || maps[0].Swap != 16*1024 {
+		t.Errorf("expected anonymous mapping first by RSS, got %+v", maps[0])
+	}
+	prog := maps[1]
+	if prog.Path != "/usr/bin/my prog" || prog.Count != 2 || prog.Size != 136*1024 || prog.RSS != 104*1024 {
+		t.Errorf("expected both program mappings summed, got %+v", prog)
+	}
+	if maps[2].Path != "[stack]" {
+		t.Errorf("expected stack last, got %+v", maps[2])
+	}
+}
+
+func TestSocketLabel(t *testing.T) {
+	cases := []struct {
+		c    psnet.ConnectionStat
+		want string
+	}{
+		{psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM,
+			Laddr: psnet.Addr{IP: "10.0.0.1", Port: 22}, Raddr: psnet.Addr{IP: "10.0.0.9", Port: 51234}, Status: "ESTABLISHED"},
+			"tcp 10.0.0.1:22 → 10.0.0.9:51234 ESTABLISHED"},
+		{psnet.ConnectionStat{Family: syscall.AF_INET6, Type: syscall.SOCK_STREAM,
+			Laddr: psnet.Addr{IP: "::", Port: 22}, Status: "LISTEN"},
+			"tcp6 [::]:22 LISTEN"},
+		{psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_DGRAM,
+			Laddr: psnet.Addr{IP: "0.0.0.0", Port: 53}, Status: "NONE"},
+			"udp 0.0.0.0:53"},
+		{psnet.ConnectionStat{Family: syscall.AF_UNIX, Laddr: psnet.Addr{IP: "/run/app.sock"}},
+			"unix /run/app.sock"},
+	}
+	for _, tc := range cases {
+		if got := socketLabel(tc.c); got != tc.want {
+			t.Errorf("socketLabel = %q, want %q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// ProcessEventLimit caps Snapshot.ProcessEvents; older events are
+// dropped first.
+const ProcessEventLimit = 500
+
+// Kinds of ProcessEvent.
+const (
+	ProcessStarted = "start"
+	ProcessExited  = "exit"
+)
+
+// ProcessEvent records a process appearing in or disappearing from the
+// process table between two process samples. A process that starts and
+// exits between samples is never seen, so it has no events.
+type ProcessEvent struct {
+	Kind string    // ProcessStarted or ProcessExited
+	Time time.Time // start: the process's create time; exit: the sample that noticed it
+	PID  int32
+	PPID int32
+	Name string
+	User string
+
+	// Lifetime runs from the create time to the last sample the process
+	// was seen in; zero for starts.
+	Lifetime time.Duration
+	// PeakCPU and PeakMem are the highest CPU% and MEM% seen across the
+	// process's samples; for starts, those of its first sample.
+	PeakCPU float64
+	PeakMem float32
+}
+
+// processEvents diffs two process caches by PID and create time. It
+// returns no events without a previous sample, so the first sample does
+// not report every process as started.
+func processEvents(prev, cur map[int32]procEntry, now time.Time) []ProcessEvent {
+	if prev == nil {
+		return nil
+	}
+	var events []ProcessEvent
+	for pid, e := range prev {
+		if c, ok := cur[pid]; ok && c.createTime == e.createTime {
+			continue
+		}
+		events = append(events, ProcessEvent{
+			Kind: ProcessExited, Time: now, PID: pid, PPID: e.ppid, Name: e.name, User: e.user,
+			Lifetime: max(e.at.Sub(time.UnixMilli(e.createTime)), 0),
+			PeakCPU:  e.peakCPU, PeakMem: e.peakMem,
+		})
+	}
+	for pid, c := range cur {
+		if e, ok := prev[pid]; ok && e.createTime == c.createTime {
+			continue
+		}
+		events = append(events, ProcessEvent{
+			Kind: ProcessStarted, Time: time.UnixMilli(c.createTime), PID: pid, PPID: c.ppid, Name: c.name, User: c.user,
+			PeakCPU: c.peakCPU, PeakMem: c.peakMem,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Time.Equal(events[j].Time) {
+			return events[i].Time.Before(events[j].Time)
+		}
+		return events[i].PID < events[j].PID
+	})
+	return events
+}
+
+// appendEvents appends events to the ring log, dropping the oldest past
+// ProcessEventLimit. log is not modified, since earlier snapshots share
+// it.
+func appendEvents(log, events []ProcessEvent) []ProcessEvent {
+	if len(events) == 0 {
+		return log
+	}
+	drop := max(len(log)+len(events)-ProcessEventLimit, 0)
+	if drop >= len(log) {
+		return append([]ProcessEvent(nil), events[drop-len(log):]...)
+	}
+	out := make([]ProcessEvent, 0, len(log)-drop+len(events))
+	out = append(out, log[drop:]...)
+	return append(out, events...)
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessEvents(t *testing.T) {
+	t0 := time.UnixMilli(1_000_000)
+	now := t0.Add(10 * time.Second)
+	prev := map[int32]procEntry{
+		10: {createTime: t0.UnixMilli(), at: t0.Add(5 * time.Second), name: "cc1", user: "alice", ppid: 9, peakCPU: 99, peakMem: 2},
+		11: {createTime: t0.UnixMilli(), at: t0.Add(5 * time.Second), name: "sshd"},
+		12: {createTime: t0.UnixMilli(), at: t0.Add(5 * time.Second), name: "old"},
+	}
+	cur := map[int32]procEntry{
+		11: prev[11],
+		12: {createTime: t0.Add(7 * time.Second).UnixMilli(), name: "new", peakCPU: 40}, // PID reused
+		13: {createTime: t0.Add(6 * time.Second).UnixMilli(), name: "ld", user: "alice", ppid: 9},
+	}
+
+	if got := processEvents(nil, cur, now); got != nil {
+		t.Errorf("first sample should report nothing, got %+v", got)
+	}
+
+	got := processEvents(prev, cur, now)
+	want := []struct {
+		kind string
+		pid  int32
+		name string
+	}{
+		{ProcessStarted, 13, "ld"},
+		{ProcessStarted, 12, "new"},
+		{ProcessExited, 10, "cc1"},
+		{ProcessExited, 12, "old"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].PID != w.pid || got[i].Name != w.name {
+			t.Errorf("event %d = %s %d %s, want %s %d %s", i, got[i].Kind, got[i].PID, got[i].Name, w.kind, w.pid, w.name)
+		}
+	}
+	if e := got[2]; e.Lifetime != 5*time.Second || e.PeakCPU != 99 || e.PeakMem != 2 || e.User != "alice" || e.PPID != 9 || !e.Time.Equal(now) {
+		t.Errorf("unexpected exit event %+v", e)
+	}
+	if e := got[1]; e.PeakCPU != 40 || !e.Time.Equal(t0.Add(7*time.Second)) {
+		t.Errorf("unexpected start event %+v", e)
+	}
+}
+
+func TestAppendEvents(t *testing.T) {
+	events := func(from, n int) []ProcessEvent {
+		var out []ProcessEvent
+		for i := range n {
+			out = append(out, ProcessEvent{PID: int32(from + i)})
+		}
+		return out
+	}
+
+	log := appendEvents(nil, events(0, ProcessEventLimit-1))
+	next := appendEvents(log, events(1000, 3))
+	if len(next) != ProcessEventLimit || next[0].PID != 2 || next[len(next)-1].PID != 1002 {
+		t.Errorf("unexpected log: len %d, first %d, last %d", len(next), next[0].PID, next[len(next)-1].PID)
+	}
+	if log[len(log)-1].PID != ProcessEventLimit-2 {
+		t.Error("earlier log was modified")
+	}
+
+	next = appendEvents(log, events(5000, ProcessEventLimit+7))
+	if len(next) != ProcessEventLimit || next[0].PID != 5007 {
+		t.Errorf("unexpected log after overflow: len %d, first %d", len(next), next[0].PID)
+	}
+}
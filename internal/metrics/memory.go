@@ -2,35 +2,93 @@ package metrics
 
 import (
 	"context"
+	"runtime"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/load"
 	"github.com/shirou/gopsutil/v4/mem"
 )
 
+func init() {
+	Register(&collectorFuncs{
+		name: "mem",
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			m, err := CollectMemory(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Memory = m }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if !previous.CollectedAt.IsZero() {
+				snap.Memory = previous.Memory
+			}
+		},
+	})
+	Register(&collectorFuncs{
+		name:  "load",
+		every: 5 * time.Second, // the kernel updates load averages every 5s
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			l, err := CollectLoad(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Load = l }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if !previous.CollectedAt.IsZero() {
+				snap.Load = previous.Load
+			}
+		},
+	})
+}
+
 func CollectMemory(ctx context.Context) (MemoryStats, error) {
 	vm, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		return MemoryStats{}, err
 	}
-
-	const gb = 1 << 30
-	ms := MemoryStats{
-		TotalGB:     float64(vm.Total) / gb,
-		UsedGB:      float64(vm.Used) / gb,
-		AvailableGB: float64(vm.Available) / gb,
-		Percent:     vm.UsedPercent,
-	}
+	ms := memoryStats(vm)
 
 	sw, err := mem.SwapMemoryWithContext(ctx)
 	if err == nil && sw.Total > 0 {
-		ms.SwapTotalGB = float64(sw.Total) / gb
-		ms.SwapUsedGB = float64(sw.Used) / gb
+		ms.SwapTotal = sw.Total
+		ms.SwapUsed = sw.Used
 		ms.SwapPercent = sw.UsedPercent
 	}
 
 	return ms, nil
 }
 
+// memoryStats converts gopsutil's reading, undoing its folding of
+// reclaimable slab into the page cache on Linux.
+func memoryStats(vm *mem.VirtualMemoryStat) MemoryStats {
+	cached := vm.Cached
+	if runtime.GOOS == "linux" && vm.Sreclaimable <= cached {
+		cached -= vm.Sreclaimable
+	}
+	return MemoryStats{
+		Total:     vm.Total,
+		Used:      vm.Used,
+		Available: vm.Available,
+		Free:      vm.Free,
+		Percent:   vm.UsedPercent,
+
+		Buffers:           vm.Buffers,
+		Cached:            cached,
+		Shared:            vm.Shared,
+		SlabReclaimable:   vm.Sreclaimable,
+		SlabUnreclaimable: vm.Sunreclaim,
+		Dirty:             vm.Dirty,
+		Writeback:         vm.WriteBack,
+		HugePagesTotal:    vm.HugePagesTotal,
+		HugePagesFree:     vm.HugePagesFree,
+		HugePageSize:      vm.HugePageSize,
+		CommittedAS:       vm.CommittedAS,
+		CommitLimit:       vm.CommitLimit,
+	}
+}
+
 func CollectLoad(ctx context.Context) (LoadAvg, error) {
 	avg, err := load.AvgWithContext(ctx)
 	if err != nil {
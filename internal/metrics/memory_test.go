@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+func TestMemoryStats(t *testing.T) {
+	vm := &mem.VirtualMemoryStat{
+		Total: 1000, Used: 300, Available: 700, Free: 200, UsedPercent: 30,
+		Buffers: 50, Cached: 450, Shared: 100, Sreclaimable: 50, Sunreclaim: 20,
+		HugePagesTotal: 4, HugePagesFree: 1, HugePageSize: 2 << 20,
+	}
+	m := memoryStats(vm)
+	if m.Total != 1000 || m.Used != 300 || m.Percent != 30 || m.SlabUnreclaimable != 20 || m.HugePagesTotal != 4 {
+		t.Errorf("unexpected stats %+v", m)
+	}
+	// gopsutil counts reclaimable slab as cache on Linux.
+	want := uint64(450)
+	if runtime.GOOS == "linux" {
+		want = 400
+	}
+	if m.Cached != want {
+		t.Errorf("Cached = %d, want %d", m.Cached, want)
+	}
+	if !m.HasBreakdown() || (MemoryStats{Total: 1000}).HasBreakdown() {
+		t.Errorf("HasBreakdown should follow buffers and cache")
+	}
+}
@@ -36,6 +36,24 @@ type InterfaceDelta struct {
 	OutSec float64 // bytes/sec sent
 }
 
+func init() {
+	Register(&collectorFuncs{
+		name: "net",
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			n, err := CollectNetwork(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Network = n }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if previous.Network.Available {
+				snap.Network = previous.Network
+			}
+		},
+	})
+}
+
 // CollectNetwork gathers network I/O counters.
 func CollectNetwork(ctx context.Context) (NetworkStats, error) {
 	counters, err := psnet.IOCountersWithContext(ctx, true)
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(&collectorFuncs{
+		name:  "psi",
+		every: 2 * time.Second, // the kernel updates the averages every 2s
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			p, err := CollectPressure(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Pressure = p }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if previous.Pressure.Available {
+				snap.Pressure = previous.Pressure
+			}
+		},
+	})
+}
+
+// PressureStats is Linux Pressure Stall Information (PSI) for CPU,
+// memory and I/O, system-wide or for one cgroup.
+type PressureStats struct {
+	Available bool
+	CPU       Pressure
+	Memory    Pressure
+	IO        Pressure
+}
+
+// Pressure is the contents of one PSI file. Some is the share of time in
+// which at least one task was stalled on the resource, Full the share in
+// which all non-idle tasks were, so nothing productive ran.
+type Pressure struct {
+	Some PressureLine
+	Full PressureLine
+}
+
+// PressureLine holds the stall percentages averaged over 10s, 60s and
+// 300s, and the total stall time in microseconds.
+type PressureLine struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// CollectPressure reads /proc/pressure. PSI is Linux only and needs a
+// kernel built with it (4.20+); elsewhere the result is not Available.
+func CollectPressure(_ context.Context) (PressureStats, error) {
+	if runtime.GOOS != "linux" {
+		return PressureStats{}, nil
+	}
+	return readPressureDir("/proc/pressure", "")
+}
+
+// readCgroupPressure reads the PSI files of a cgroup v2 directory.
+func readCgroupPressure(dir string) PressureStats {
+	p, _ := readPressureDir(dir, ".pressure")
+	return p
+}
+
+// readPressureDir reads the cpu, memory and io PSI files in dir, named
+// with suffix. A missing directory means PSI is unavailable rather than
+// an error.
+func readPressureDir(dir, suffix string) (PressureStats, error) {
+	var ps PressureStats
+	for _, r := range []struct {
+		name string
+		dst  *Pressure
+	}{
+		{"cpu", &ps.CPU}, {"memory", &ps.Memory}, {"io", &ps.IO},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, r.name+suffix))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return PressureStats{}, err
+		}
+		p, err := ParsePressure(string(data))
+		if err != nil {
+			return PressureStats{}, fmt.Errorf("%s: %w", r.name, err)
+		}
+		*r.dst = p
+		ps.Available = true
+	}
+	return ps, nil
+}
+
+// ParsePressure parses a PSI file such as /proc/pressure/io:
+//
+//	some avg10=0.12 avg60=0.02 avg300=0.00 total=5796575
+//	full avg10=0.12 avg60=0.02 avg300=0.00 total=4521871
+//
+// The full line is absent for system-wide CPU on kernels before 5.13.
+func ParsePressure(data string) (Pressure, error) {
+	var p Pressure
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var dst *PressureLine
+		switch fields[0] {
+		case "some":
+			dst = &p.Some
+		case "full":
+			dst = &p.Full
+		default:
+			return Pressure{}, fmt.Errorf("unexpected line %q", line)
+		}
+		for _, f := range fields[1:] {
+			key, val, _ := strings.Cut(f, "=")
+			var err error
+			switch key {
+			case "avg10":
+				dst.Avg10, err = strconv.ParseFloat(val, 64)
+			case "avg60":
+				dst.Avg60, err = strconv.ParseFloat(val, 64)
+			case "avg300":
+				dst.Avg300, err = strconv.ParseFloat(val, 64)
+			case "total":
+				dst.Total, err = strconv.ParseUint(val, 10, 64)
+			}
+			if err != nil {
+				return Pressure{}, fmt.Errorf("bad %s in %q", key, line)
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePressure(t *testing.T) {
+	p, err := ParsePressure("some avg10=1.57 avg60=2.67 avg300=2.13 total=111916239\n" +
+		"full avg10=0.50 avg60=0.00 avg300=0.00 total=42\n")
+	if err != nil {
+		t.Fatalf("ParsePressure: %v", err)
+	}
+	want := Pressure{
+		Some: PressureLine{Avg10: 1.57, Avg60: 2.67, Avg300: 2.13, Total: 111916239},
+		Full: PressureLine{Avg10: 0.5, Total: 42},
+	}
+	if p != want {
+		t.Errorf("got %+v, want %+v", p, want)
+	}
+
+	// System-wide CPU has no full line before Linux 5.13.
+	if p, err := ParsePressure("some avg10=3.00 avg60=0.00 avg300=0.00 total=9\n"); err != nil || p.Some.Avg10 != 3 || p.Full != (PressureLine{}) {
+		t.Errorf("unexpected result without full line: %+v, %v", p, err)
+	}
+
+	for _, bad := range []string{"half avg10=1.00", "some avg10=x"} {
+		if _, err := ParsePressure(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestReadPressureDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"memory.pressure": "some avg10=12.00 avg60=0.00 avg300=0.00 total=1\nfull avg10=6.00 avg60=0.00 avg300=0.00 total=1",
+	})
+	ps, err := readPressureDir(dir, ".pressure")
+	if err != nil {
+		t.Fatalf("readPressureDir: %v", err)
+	}
+	if !ps.Available || ps.Memory.Some.Avg10 != 12 || ps.Memory.Full.Avg10 != 6 || ps.CPU != (Pressure{}) {
+		t.Errorf("unexpected pressure %+v", ps)
+	}
+
+	if ps, err := readPressureDir(filepath.Join(dir, "missing"), ""); err != nil || ps.Available {
+		t.Errorf("expected unavailable PSI without error, got %+v, %v", ps, err)
+	}
+}
@@ -2,99 +2,355 @@ package metrics
 
 import (
 	"context"
-	"sort"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/shirou/gopsutil/v4/process"
 )
 
-type SortField int
-
-const (
-	SortByCPU SortField = iota
-	SortByMem
-	SortByPID
-)
+func init() {
+	Register(&collectorFuncs{
+		name:     "proc",
+		interval: processInterval,
+		collect:  collectProcessesForSnapshot,
+		fallback: fallbackProcesses,
+	})
+}
 
-type processSample struct {
-	process *process.Process
-	pid     int32
-	cpu     float64
-	mem     float32
+// processInterval samples processes on the configured process cadence,
+// but immediately when there is no previous sample or the sort order
+// changed, since the processes whose details were read no longer lead
+// the list.
+func processInterval(req Request) time.Duration {
+	if len(req.Previous.Processes) == 0 ||
+		req.Previous.ProcessSortBy != req.SortBy ||
+		req.Previous.ProcessSortReverse != req.Options.SortReverse {
+		return SampleNow
+	}
+	return req.ProcessSampleEvery
 }
 
-func CollectProcesses(ctx context.Context, sortBy SortField, limit int) ([]ProcessInfo, error) {
-	procs, err := process.ProcessesWithContext(ctx)
+func collectProcessesForSnapshot(ctx context.Context, req Request) (func(*Snapshot), error) {
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	p, cache, err := collectProcesses(ctx, req.SortBy, req.Options.SortReverse, req.DetailLimit, req.Previous.procCache, now)
 	if err != nil {
 		return nil, err
 	}
+	return func(s *Snapshot) {
+		s.Processes = p
+		s.ProcessSortBy = req.SortBy
+		s.ProcessSortReverse = req.Options.SortReverse
+		s.procCache = cache
+		s.ProcessEvents = appendEvents(req.Previous.ProcessEvents, processEvents(req.Previous.procCache, cache, now))
+	}, nil
+}
+
+func fallbackProcesses(snap *Snapshot, previous Snapshot) {
+	if len(previous.Processes) > 0 {
+		snap.Processes = previous.Processes
+		snap.ProcessSortBy = previous.ProcessSortBy
+		snap.ProcessSortReverse = previous.ProcessSortReverse
+	}
+	snap.procCache = previous.procCache
+	snap.ProcessEvents = previous.ProcessEvents
+}
+
+// procEntry is what is kept of a process between samples: its handle,
+// which caches static fields such as the name, and the cumulative
+// counters CPU% and I/O rates are computed from. A PID whose create time
+// changed belongs to a new process and starts a new entry.
+type procEntry struct {
+	proc       *process.Process
+	createTime int64   // milliseconds since epoch
+	cpuTime    float64 // user + system seconds
+	io         ioSample
+	at         time.Time
+
+	// What a ProcessExited event reports once the process is gone.
+	ppid       int32
+	name, user string
+	peakCPU    float64
+	peakMem    float32
+}
+
+// ioSample is a process's cumulative I/O counters at one point in time,
+// kept between process samples to compute rates.
+type ioSample struct {
+	read, write uint64
+	at          time.Time
+}
+
+// CollectProcesses returns every process sorted by sortBy, with the
+// details of the first detailLimit read. Without a previous sample, CPU%
+// is the average over each process's lifetime and I/O rates are zero.
+func CollectProcesses(ctx context.Context, sortBy SortField, detailLimit int) ([]ProcessInfo, error) {
+	infos, _, err := collectProcesses(ctx, sortBy, false, detailLimit, nil, time.Now())
+	return infos, err
+}
+
+func collectProcesses(ctx context.Context, sortBy SortField, reverse bool, detailLimit int, prev map[int32]procEntry, now time.Time) ([]ProcessInfo, map[int32]procEntry, error) {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var memTotal uint64
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		memTotal = vm.Total
+	}
 
-	samples := make([]processSample, 0, len(procs))
-	for _, p := range procs {
+	infos := make([]ProcessInfo, 0, len(pids))
+	cache := make(map[int32]procEntry, len(pids))
+	for _, pid := range pids {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
 		default:
 		}
 
-		cpuPct, cpuErr := p.CPUPercentWithContext(ctx)
-		memPct, memErr := p.MemoryPercentWithContext(ctx)
-		if cpuErr != nil && memErr != nil {
+		entry, ok := sampleProcess(ctx, pid, prev, now)
+		if !ok {
 			continue
 		}
 
-		samples = append(samples, processSample{
-			process: p,
-			pid:     p.Pid,
-			cpu:     cpuPct,
-			mem:     memPct,
-		})
+		info := readProcessInfo(ctx, entry.proc)
+		info.CreateTime = entry.createTime
+		info.CPUPercent = cpuPercent(prev[pid], entry)
+		if memTotal > 0 {
+			info.MemPercent = float32(100 * float64(info.RSS) / float64(memTotal))
+		}
+		if !entry.io.at.IsZero() {
+			var last ioSample
+			if p, ok := prev[pid]; ok && p.createTime == entry.createTime {
+				last = p.io
+			}
+			info.ReadRate, info.WriteRate = ioRates(last, entry.io)
+		}
+		entry.ppid, entry.name, entry.user = info.PPID, info.Name, info.User
+		entry.peakCPU, entry.peakMem = info.CPUPercent, info.MemPercent
+		if p, ok := prev[pid]; ok && p.createTime == entry.createTime {
+			entry.peakCPU, entry.peakMem = max(entry.peakCPU, p.peakCPU), max(entry.peakMem, p.peakMem)
+		}
+		infos = append(infos, info)
+		cache[pid] = entry
 	}
 
-	sort.Slice(samples, func(i, j int) bool {
-		switch sortBy {
-		case SortByMem:
-			return samples[i].mem > samples[j].mem
-		case SortByPID:
-			return samples[i].pid < samples[j].pid
-		default:
-			return samples[i].cpu > samples[j].cpu
-		}
-	})
+	SortProcesses(infos, sortBy, reverse)
 
-	if limit > 0 && limit < len(samples) {
-		samples = samples[:limit]
+	for i := 0; i < detailLimit && i < len(infos); i++ {
+		infos[i].ProcessDetails = readDetails(ctx, cache[infos[i].PID].proc)
 	}
 
-	infos := make([]ProcessInfo, 0, len(samples))
-	for _, sample := range samples {
-		name, _ := sample.process.NameWithContext(ctx)
-		if name == "" {
-			name = "?"
-		}
-		user, _ := sample.process.UsernameWithContext(ctx)
-		ppid, _ := sample.process.PpidWithContext(ctx)
+	return infos, cache, nil
+}
 
-		var state string
-		if ss, err := sample.process.StatusWithContext(ctx); err == nil && len(ss) > 0 {
-			state = ss[0]
-		}
+// sampleProcess reads pid's counters, reusing its handle from prev when
+// the PID still belongs to the same process. It reports false when the
+// process exited or its CPU times are unreadable.
+func sampleProcess(ctx context.Context, pid int32, prev map[int32]procEntry, now time.Time) (procEntry, bool) {
+	// A fresh handle reads the current create time; the cached one
+	// would return the create time of the PID's previous owner.
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return procEntry{}, false
+	}
+	createTime, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		return procEntry{}, false
+	}
+	if old, ok := prev[pid]; ok && old.createTime == createTime {
+		p = old.proc
+	}
+	times, err := p.TimesWithContext(ctx)
+	if err != nil || times == nil {
+		return procEntry{}, false
+	}
 
-		var threads int32
-		if t, err := sample.process.NumThreadsWithContext(ctx); err == nil {
-			threads = t
+	entry := procEntry{
+		proc:       p,
+		createTime: createTime,
+		cpuTime:    times.User + times.System,
+		at:         now,
+	}
+	if cur, ok := readIOCounters(ctx, p, now); ok {
+		entry.io = cur
+	}
+	return entry, true
+}
+
+// cpuPercent returns a process's CPU% since its previous sample, where
+// 100% is one fully used core, as top reports it. A process seen for the
+// first time gets its average over its lifetime instead.
+func cpuPercent(prev, cur procEntry) float64 {
+	if prev.proc == nil || prev.createTime != cur.createTime {
+		secs := cur.at.Sub(time.UnixMilli(cur.createTime)).Seconds()
+		if secs <= 0 {
+			return 0
 		}
+		return 100 * cur.cpuTime / secs
+	}
+	secs := cur.at.Sub(prev.at).Seconds()
+	if secs <= 0 || cur.cpuTime < prev.cpuTime {
+		return 0
+	}
+	return 100 * (cur.cpuTime - prev.cpuTime) / secs
+}
+
+// readProcessInfo reads the fields of p that are cheap enough to read for
+// every process: identity, state and memory size. Each is best-effort.
+// CPU%, memory %, create time and I/O rates are left to the caller.
+func readProcessInfo(ctx context.Context, p *process.Process) ProcessInfo {
+	name, _ := p.NameWithContext(ctx)
+	if name == "" {
+		name = "?"
+	}
+	user, _ := p.UsernameWithContext(ctx)
+	ppid, _ := p.PpidWithContext(ctx)
+
+	var state string
+	if ss, err := p.StatusWithContext(ctx); err == nil && len(ss) > 0 {
+		state = ss[0]
+	}
 
-		infos = append(infos, ProcessInfo{
-			PID:        sample.pid,
-			PPID:       ppid,
-			Name:       name,
-			User:       user,
-			CPUPercent: sample.cpu,
-			MemPercent: sample.mem,
-			State:      state,
-			NumThreads: threads,
-		})
+	var threads int32
+	if t, err := p.NumThreadsWithContext(ctx); err == nil {
+		threads = t
 	}
 
-	return infos, nil
+	cgroup := readCgroup(p.Pid)
+	container, unit := classifyCgroup(cgroup)
+
+	info := ProcessInfo{
+		PID:        p.Pid,
+		PPID:       ppid,
+		Name:       name,
+		User:       user,
+		State:      state,
+		NumThreads: threads,
+		Cgroup:     cgroup,
+		Container:  container,
+		Unit:       unit,
+	}
+	if mi, err := p.MemoryInfoWithContext(ctx); err == nil && mi != nil {
+		info.RSS = mi.RSS
+		info.VMS = mi.VMS
+	}
+	return info
+}
+
+// ReadProcessDetails reads the details of one process, for rows the
+// collector did not read them for. Unreadable fields stay zero.
+func ReadProcessDetails(ctx context.Context, pid int32) ProcessDetails {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return ProcessDetails{}
+	}
+	return readDetails(ctx, p)
+}
+
+// readDetails reads the fields behind the optional process table
+// columns. Each is best-effort: unreadable fields stay zero.
+func readDetails(ctx context.Context, p *process.Process) ProcessDetails {
+	var d ProcessDetails
+	if nice, err := p.NiceWithContext(ctx); err == nil {
+		d.Nice = nice
+	}
+	d.Priority = readPriority(p.Pid)
+	if t, err := p.TimesWithContext(ctx); err == nil && t != nil {
+		d.CPUTime = t.User + t.System
+	}
+	if cmd, err := p.CmdlineWithContext(ctx); err == nil {
+		d.Cmdline = cmd
+	}
+	if fds, err := p.NumFDsWithContext(ctx); err == nil {
+		d.NumFDs = fds
+	}
+	return d
+}
+
+// readPriority returns the kernel priority from /proc/<pid>/stat, or 0
+// where it is not available.
+func readPriority(pid int32) int32 {
+	if runtime.GOOS != "linux" {
+		return 0
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/stat")
+	if err != nil {
+		return 0
+	}
+	return parseStatPriority(string(data))
+}
+
+// parseStatPriority extracts field 18 (priority) of /proc/<pid>/stat. The
+// command name (field 2) may contain spaces and parentheses, so fields
+// are counted from the last ')'.
+func parseStatPriority(stat string) int32 {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0
+	}
+	fields := strings.Fields(stat[end+1:])
+	const priorityField = 18 - 3 // fields after ')' start at field 3
+	if len(fields) <= priorityField {
+		return 0
+	}
+	prio, err := strconv.ParseInt(fields[priorityField], 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(prio)
+}
+
+// readIOCounters reads p's cumulative storage I/O. On Linux this is the
+// read_bytes/write_bytes pair from /proc/<pid>/io, which is what the disk
+// panel measures; elsewhere gopsutil only has the all-I/O counters.
+// Reading another user's counters usually needs root.
+func readIOCounters(ctx context.Context, p *process.Process, now time.Time) (ioSample, bool) {
+	c, err := p.IOCountersWithContext(ctx)
+	if err != nil || c == nil {
+		return ioSample{}, false
+	}
+	if runtime.GOOS == "linux" {
+		return ioSample{read: c.DiskReadBytes, write: c.DiskWriteBytes, at: now}, true
+	}
+	return ioSample{read: c.ReadBytes, write: c.WriteBytes, at: now}, true
+}
+
+// ioRates returns bytes/s between two samples, or zeros if there is no
+// usable previous sample (first sight, or counters went backwards
+// because the PID was reused).
+func ioRates(prev, cur ioSample) (read, write float64) {
+	secs := cur.at.Sub(prev.at).Seconds()
+	if prev.at.IsZero() || secs <= 0 || cur.read < prev.read || cur.write < prev.write {
+		return 0, 0
+	}
+	return float64(cur.read-prev.read) / secs, float64(cur.write-prev.write) / secs
+}
+
+// StateLetter returns the one-letter code of a process state, as shown
+// by ps: R, S, D, Z or T.
+func StateLetter(state string) string {
+	switch state {
+	case "running":
+		return "R"
+	case "sleeping", "sleep", "idle":
+		return "S"
+	case "zombie":
+		return "Z"
+	case "stopped", "stop":
+		return "T"
+	case "disk-sleep", "blocked":
+		return "D"
+	default:
+		if len(state) > 0 {
+			return string([]rune(state)[0:1])
+		}
+		return "?"
+	}
 }
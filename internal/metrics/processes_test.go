@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+func TestParseStatPriority(t *testing.T) {
+	stat := "1234 (my (odd) proc) S 1 1234 1234 0 -1 4194560 100 0 0 0 5 3 0 0 20 0 1 0 12345 1000 100 18446744073709551615\n"
+	if got := parseStatPriority(stat); got != 20 {
+		t.Errorf("expected priority 20, got %d", got)
+	}
+	rt := "42 (rt) S 2 0 0 0 -1 0 0 0 0 0 0 0 0 0 -51 0 1 0 1 0 0\n"
+	if got := parseStatPriority(rt); got != -51 {
+		t.Errorf("expected realtime priority -51, got %d", got)
+	}
+	if got := parseStatPriority("garbage"); got != 0 {
+		t.Errorf("expected 0 for unparseable stat, got %d", got)
+	}
+}
+
+func TestSortProcesses_Directions(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 3, Name: "beta", RSS: 10},
+		{PID: 1, Name: "Alpha", RSS: 30},
+		{PID: 2, Name: "gamma", RSS: 20},
+	}
+	pids := func() [3]int32 { return [3]int32{procs[0].PID, procs[1].PID, procs[2].PID} }
+
+	SortProcesses(procs, SortByName, false)
+	if got := pids(); got != [3]int32{1, 3, 2} {
+		t.Errorf("name ascending (case-insensitive): got %v", got)
+	}
+	SortProcesses(procs, SortByName, true)
+	if got := pids(); got != [3]int32{2, 3, 1} {
+		t.Errorf("name reversed: got %v", got)
+	}
+	SortProcesses(procs, SortByRSS, false)
+	if got := pids(); got != [3]int32{1, 2, 3} {
+		t.Errorf("RSS descending: got %v", got)
+	}
+	SortProcesses(procs, SortByRSS, true)
+	if got := pids(); got != [3]int32{3, 2, 1} {
+		t.Errorf("RSS reversed: got %v", got)
+	}
+}
+
+func TestSortField_Descending(t *testing.T) {
+	if !SortByCPU.Descending(false) || SortByCPU.Descending(true) {
+		t.Errorf("CPU should default to descending")
+	}
+	if SortByPID.Descending(false) || !SortByPID.Descending(true) {
+		t.Errorf("PID should default to ascending")
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	t0 := time.UnixMilli(time.Now().UnixMilli()) // create times have millisecond precision
+	created := t0.Add(-100 * time.Second).UnixMilli()
+	handle := &process.Process{Pid: 42}
+	prev := procEntry{proc: handle, createTime: created, cpuTime: 10, at: t0}
+	cur := procEntry{proc: handle, createTime: created, cpuTime: 13, at: t0.Add(2 * time.Second)}
+
+	if got := cpuPercent(prev, cur); math.Abs(got-150) > 1e-9 {
+		t.Fatalf("expected 150%% (1.5 cores) from the delta, got %v", got)
+	}
+
+	// First sight: average over the process lifetime.
+	first := procEntry{proc: handle, createTime: created, cpuTime: 10, at: t0}
+	if got := cpuPercent(procEntry{}, first); math.Abs(got-10) > 1e-9 {
+		t.Fatalf("expected lifetime average of 10%%, got %v", got)
+	}
+
+	// PID reused by a younger process: the old entry must be ignored.
+	reused := procEntry{proc: handle, createTime: t0.Add(-time.Second).UnixMilli(), cpuTime: 0.5, at: t0.Add(2 * time.Second)}
+	if got := cpuPercent(prev, reused); math.Abs(got-(100*0.5/3)) > 1e-9 {
+		t.Fatalf("expected reused PID to use its own lifetime, got %v", got)
+	}
+
+	cur.cpuTime = 5
+	if got := cpuPercent(prev, cur); got != 0 {
+		t.Fatalf("expected 0 when CPU time went backwards, got %v", got)
+	}
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SampleNow is returned by Collector.Interval when the previous values
+// cannot be reused, so the collector samples on this tick whatever
+// interval is configured for it.
+const SampleNow time.Duration = -1
+
+// Collector gathers one subsystem of a Snapshot. Built-in collectors
+// register themselves from an init function in their own file; other
+// packages may call Register to add their own.
+type Collector interface {
+	// Name is the short label used in CollectionStatus and the stale
+	// indicator (e.g. "cpu", "proc").
+	Name() string
+
+	// Enabled reports whether the collector should run for opts.
+	Enabled(opts CollectOptions) bool
+
+	// Interval is the minimum time between two samples. Until it has
+	// elapsed the scheduler reuses the previous values via Fallback.
+	// Zero means sample on every tick. CollectOptions.Intervals overrides
+	// it, except for SampleNow.
+	Interval(req Request) time.Duration
+
+	// Collect gathers fresh metrics. The returned function stores them
+	// into the snapshot and is called while the snapshot is locked, so
+	// it must not block. A nil function means there is nothing to store.
+	Collect(ctx context.Context, req Request) (func(*Snapshot), error)
+
+	// Fallback carries values from previous into snap, either because
+	// Collect failed or because the collector is not due yet. It decides
+	// whether the previous values are still worth showing.
+	Fallback(snap *Snapshot, previous Snapshot)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Collector
+)
+
+// Register adds c to the collectors run by Collect. Collectors run in
+// registration order as far as status reporting is concerned. Register
+// panics if a collector with the same name is already registered.
+func Register(c Collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registry {
+		if existing.Name() == c.Name() {
+			panic(fmt.Sprintf("metrics: collector %q registered twice", c.Name()))
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Collectors returns the registered collectors in registration order.
+func Collectors() []Collector {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]Collector, len(registry))
+	copy(out, registry)
+	return out
+}
+
+// collectorFuncs adapts plain functions to the Collector interface so the
+// built-in collectors can keep their wiring next to the collection code.
+type collectorFuncs struct {
+	name     string
+	enabled  func(CollectOptions) bool   // nil = always enabled
+	every    time.Duration               // fixed sampling interval
+	interval func(Request) time.Duration // overrides every when set
+	collect  func(context.Context, Request) (func(*Snapshot), error)
+	fallback func(snap *Snapshot, previous Snapshot)
+}
+
+func (c *collectorFuncs) Name() string { return c.name }
+
+func (c *collectorFuncs) Enabled(opts CollectOptions) bool {
+	return c.enabled == nil || c.enabled(opts)
+}
+
+func (c *collectorFuncs) Interval(req Request) time.Duration {
+	if c.interval != nil {
+		return c.interval(req)
+	}
+	return c.every
+}
+
+func (c *collectorFuncs) Collect(ctx context.Context, req Request) (func(*Snapshot), error) {
+	return c.collect(ctx, req)
+}
+
+func (c *collectorFuncs) Fallback(snap *Snapshot, previous Snapshot) {
+	if c.fallback != nil {
+		c.fallback(snap, previous)
+	}
+}
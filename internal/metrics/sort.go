@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+type SortField int
+
+const (
+	SortByCPU SortField = iota
+	SortByMem
+	SortByPID
+	SortByIO // read + write bytes/s
+	SortByUser
+	SortByName
+	SortByThreads
+	SortByState
+	SortByStart // process start time
+	SortByRSS
+)
+
+// DefaultDescending reports the direction a field sorts in before it is
+// reversed: quantities and start time largest first, PID and text
+// ascending.
+func (f SortField) DefaultDescending() bool {
+	switch f {
+	case SortByPID, SortByUser, SortByName, SortByState:
+		return false
+	default:
+		return true
+	}
+}
+
+// Descending reports the effective direction of f, optionally reversed.
+func (f SortField) Descending(reverse bool) bool {
+	return f.DefaultDescending() != reverse
+}
+
+func (f SortField) textual() bool {
+	return f == SortByUser || f == SortByName || f == SortByState
+}
+
+// sortKey is the value a process is ordered by for one sort field.
+type sortKey struct {
+	num  float64
+	text string
+}
+
+func processSortKey(p ProcessInfo, f SortField) sortKey {
+	switch f {
+	case SortByMem:
+		return sortKey{num: float64(p.MemPercent)}
+	case SortByPID:
+		return sortKey{num: float64(p.PID)}
+	case SortByIO:
+		return sortKey{num: p.ReadRate + p.WriteRate}
+	case SortByUser:
+		return sortKey{text: strings.ToLower(p.User)}
+	case SortByName:
+		return sortKey{text: strings.ToLower(p.Name)}
+	case SortByThreads:
+		return sortKey{num: float64(p.NumThreads)}
+	case SortByState:
+		return sortKey{text: p.State}
+	case SortByStart:
+		return sortKey{num: float64(p.CreateTime)}
+	case SortByRSS:
+		return sortKey{num: float64(p.RSS)}
+	default:
+		return sortKey{num: p.CPUPercent}
+	}
+}
+
+// lessKey orders two keys for f in its effective direction.
+func lessKey(a, b sortKey, f SortField, reverse bool) bool {
+	desc := f.Descending(reverse)
+	if f.textual() {
+		if desc {
+			return a.text > b.text
+		}
+		return a.text < b.text
+	}
+	if desc {
+		return a.num > b.num
+	}
+	return a.num < b.num
+}
+
+// SortProcesses sorts procs in place by sortBy, in the same order
+// CollectProcesses uses. reverse flips the field's default direction.
+func SortProcesses(procs []ProcessInfo, sortBy SortField, reverse bool) {
+	sort.SliceStable(procs, func(i, j int) bool {
+		return lessKey(processSortKey(procs[i], sortBy), processSortKey(procs[j], sortBy), sortBy, reverse)
+	})
+}
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/sensors"
 )
@@ -21,6 +22,26 @@ type TemperatureStats struct {
 	GPUTemp   float64 // best-effort GPU temperature (highest "gpu" sensor)
 }
 
+func init() {
+	Register(&collectorFuncs{
+		name:    "temp",
+		every:   5 * time.Second,
+		enabled: func(opts CollectOptions) bool { return !opts.SkipTemp },
+		collect: func(ctx context.Context, _ Request) (func(*Snapshot), error) {
+			t, err := CollectTemperature(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return func(s *Snapshot) { s.Temperature = t }, nil
+		},
+		fallback: func(snap *Snapshot, previous Snapshot) {
+			if previous.Temperature.Available {
+				snap.Temperature = previous.Temperature
+			}
+		},
+	})
+}
+
 // CollectTemperature gathers temperature readings from available sensors.
 // Works on Linux (/sys/class/hwmon) and macOS (IOKit) via gopsutil.
 // Returns TemperatureStats with Available=false if no sensors found.
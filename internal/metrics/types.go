@@ -1,26 +1,84 @@
 package metrics
 
 import (
+	"sort"
 	"time"
 
+	"github.com/shirou/gopsutil/v4/cpu"
+
 	"github.com/youhide/hideTop/internal/metrics/gpu"
 )
 
+// CPUStats is CPU usage from the change in cumulative per-core CPU times
+// since the previous sample, or since boot without one.
 type CPUStats struct {
-	PerCore []float64
-	Total   float64
+	PerCore []float64 // busy %, per core
+	Total   float64   // busy %, all cores
+
+	// PerCoreTimes and Times break the same time down by CPU state.
+	PerCoreTimes []CPUTimes
+	Times        CPUTimes
+
+	// Cores describes the CPUs behind PerCore, index for index (Linux
+	// only).
+	Cores []CPUCore
+}
+
+// CPUTimes is how CPU time was spent over a sample interval, in percent
+// of it. Fields a platform does not report stay zero. Guest time is not
+// counted in User and Nice, though Linux reports it in both.
+type CPUTimes struct {
+	User    float64
+	Nice    float64
+	System  float64
+	Idle    float64
+	IOWait  float64
+	IRQ     float64
+	SoftIRQ float64
+	Steal   float64
+	Guest   float64
 }
 
+// Busy returns the percentage of time not spent idle or waiting for I/O.
+func (t CPUTimes) Busy() float64 {
+	return t.User + t.Nice + t.System + t.IRQ + t.SoftIRQ + t.Steal + t.Guest
+}
+
+// MemoryStats is physical memory and swap usage in bytes. Used is Total
+// minus Available: memory that cannot be reclaimed without swapping.
+// The breakdown below it is what the system reports (in full on Linux,
+// from /proc/meminfo); the rest stays zero.
 type MemoryStats struct {
-	TotalGB     float64
-	UsedGB      float64
-	AvailableGB float64
-	Percent     float64
-	SwapTotalGB float64
-	SwapUsedGB  float64
+	Total     uint64
+	Used      uint64
+	Available uint64
+	Free      uint64
+	Percent   float64
+
+	Buffers           uint64
+	Cached            uint64 // page cache, Shared included
+	Shared            uint64 // tmpfs and shared memory
+	SlabReclaimable   uint64
+	SlabUnreclaimable uint64
+	Dirty             uint64
+	Writeback         uint64
+	HugePagesTotal    uint64 // pages of HugePageSize bytes
+	HugePagesFree     uint64
+	HugePageSize      uint64
+	CommittedAS       uint64 // memory promised to processes
+	CommitLimit       uint64 // what can be promised with strict overcommit
+
+	SwapTotal   uint64
+	SwapUsed    uint64
 	SwapPercent float64
 }
 
+// HasBreakdown reports whether the system split out buffers and page
+// cache, which the used / buffers / shared / cache breakdown needs.
+func (m MemoryStats) HasBreakdown() bool {
+	return m.Buffers > 0 || m.Cached > 0
+}
+
 type LoadAvg struct {
 	Load1  float64
 	Load5  float64
@@ -36,6 +94,37 @@ type ProcessInfo struct {
 	MemPercent float32
 	State      string // R=running, S=sleeping, Z=zombie, T=stopped
 	NumThreads int32
+
+	// Cgroup is the process's cgroup path (Linux only). Container and
+	// Unit are derived from it: a "runtime/shortid" container ID and the
+	// innermost systemd service or scope.
+	Cgroup    string
+	Container string
+	Unit      string
+
+	// ReadRate and WriteRate are storage I/O in bytes/s since the
+	// previous process sample; zero on the first sample or when the
+	// counters are not readable.
+	ReadRate  float64
+	WriteRate float64
+
+	RSS        uint64 // resident set size, bytes
+	VMS        uint64 // virtual memory size, bytes
+	CreateTime int64  // milliseconds since epoch
+
+	ProcessDetails
+}
+
+// ProcessDetails holds the process fields that are too costly to read for
+// the whole process table. The collector fills them for the first few
+// processes only; ReadProcessDetails reads them for any other row that
+// is being shown.
+type ProcessDetails struct {
+	Nice     int32   // scheduling niceness, -20 (highest priority) to 19
+	Priority int32   // kernel scheduling priority (Linux only)
+	CPUTime  float64 // user + system CPU seconds
+	Cmdline  string  // full command line, arguments joined by spaces
+	NumFDs   int32   // open file descriptors; zero when not readable
 }
 
 type MetricStatus struct {
@@ -43,58 +132,46 @@ type MetricStatus struct {
 	Error string
 }
 
-type CollectionStatus struct {
-	CPU         MetricStatus
-	Memory      MetricStatus
-	Load        MetricStatus
-	Processes   MetricStatus
-	GPU         MetricStatus
-	Temperature MetricStatus
-	Network     MetricStatus
-	Disk        MetricStatus
-	Battery     MetricStatus
-}
+// CollectionStatus maps collector names to the outcome of their most
+// recent run. Collectors that succeeded may be absent.
+type CollectionStatus map[string]MetricStatus
 
 func (s CollectionStatus) HasStale() bool {
-	return s.CPU.Stale || s.Memory.Stale || s.Load.Stale || s.Processes.Stale || s.GPU.Stale || s.Temperature.Stale || s.Network.Stale || s.Disk.Stale || s.Battery.Stale
+	for _, st := range s {
+		if st.Stale {
+			return true
+		}
+	}
+	return false
 }
 
+// StaleMetrics returns the names of stale collectors in registration
+// order, followed by any unregistered names in alphabetical order.
 func (s CollectionStatus) StaleMetrics() []string {
-	stale := make([]string, 0, 5)
-	if s.CPU.Stale {
-		stale = append(stale, "cpu")
-	}
-	if s.Memory.Stale {
-		stale = append(stale, "mem")
-	}
-	if s.Load.Stale {
-		stale = append(stale, "load")
-	}
-	if s.Processes.Stale {
-		stale = append(stale, "proc")
-	}
-	if s.GPU.Stale {
-		stale = append(stale, "gpu")
+	stale := make([]string, 0, len(s))
+	seen := make(map[string]bool, len(s))
+	for _, c := range Collectors() {
+		name := c.Name()
+		seen[name] = true
+		if s[name].Stale {
+			stale = append(stale, name)
+		}
 	}
-	if s.Temperature.Stale {
-		stale = append(stale, "temp")
+	var unknown []string
+	for name, st := range s {
+		if st.Stale && !seen[name] {
+			unknown = append(unknown, name)
+		}
 	}
-	if s.Network.Stale {
-		stale = append(stale, "net")
-	}
-	if s.Disk.Stale {
-		stale = append(stale, "disk")
-	}
-	if s.Battery.Stale {
-		stale = append(stale, "bat")
-	}
-	return stale
+	sort.Strings(unknown)
+	return append(stale, unknown...)
 }
 
 type Snapshot struct {
 	CPU         CPUStats
 	Memory      MemoryStats
 	Load        LoadAvg
+	Pressure    PressureStats
 	Processes   []ProcessInfo
 	GPU         *gpu.Stats
 	Temperature TemperatureStats
@@ -102,8 +179,46 @@ type Snapshot struct {
 	Disk        DiskStats
 	Battery     BatteryStats
 
-	CollectedAt     time.Time
-	ProcessSampleAt time.Time
-	ProcessSortBy   SortField
-	Status          CollectionStatus
+	// Extra holds values from collectors registered outside this
+	// package, keyed by a name of the collector's choosing.
+	Extra map[string]float64
+
+	CollectedAt   time.Time
+	ProcessSortBy SortField
+	// ProcessSortReverse reports whether Processes are in the reverse of
+	// ProcessSortBy's default direction.
+	ProcessSortReverse bool
+	Status             CollectionStatus
+
+	// SampledAt records, per collector name, when the value carried in
+	// this snapshot was actually sampled. Collectors that were not due
+	// keep the timestamp of their earlier sample.
+	SampledAt map[string]time.Time
+
+	// ProcessEvents is a log of the most recent process starts and exits,
+	// oldest first, carried from snapshot to snapshot and capped at
+	// ProcessEventLimit.
+	ProcessEvents []ProcessEvent
+
+	// cpuTimes holds every core's cumulative CPU times from the last CPU
+	// sample so the next one can compute CPUStats.PerCoreTimes.
+	cpuTimes []cpu.TimesStat
+
+	// cpuClocksAt is when the clocks in CPU.Cores were last read; they
+	// are carried over between reads cpuClockEvery apart.
+	cpuClocksAt time.Time
+
+	// procCache holds every process's handle and counters from the last
+	// process sample so the next one can compute CPU% and I/O rates.
+	// Unexported so it stays out of exports, recordings and the remote
+	// protocol.
+	procCache map[int32]procEntry
+}
+
+// SetExtra stores an Extra value, allocating the map on first use.
+func (s *Snapshot) SetExtra(key string, v float64) {
+	if s.Extra == nil {
+		s.Extra = make(map[string]float64)
+	}
+	s.Extra[key] = v
 }
@@ -0,0 +1,40 @@
+package procctl
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Affinity returns the CPUs pid may run on.
+func Affinity(pid int) ([]int, error) {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(pid, &set); err != nil {
+		return nil, err
+	}
+	var cpus []int
+	for cpu := 0; cpu < len(set)*64; cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// SetAffinity restricts pid to the given CPUs, like taskset -p.
+func SetAffinity(pid int, cpus []int) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to set affinity of PID %d", pid)
+	}
+	if len(cpus) == 0 {
+		return fmt.Errorf("no CPUs given")
+	}
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= len(set)*64 {
+			return fmt.Errorf("CPU %d out of range", cpu)
+		}
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(pid, &set)
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package procctl
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Affinity is only supported on Linux.
+func Affinity(pid int) ([]int, error) {
+	return nil, fmt.Errorf("CPU affinity is not supported on %s", runtime.GOOS)
+}
+
+// SetAffinity is only supported on Linux.
+func SetAffinity(pid int, cpus []int) error {
+	return fmt.Errorf("CPU affinity is not supported on %s", runtime.GOOS)
+}
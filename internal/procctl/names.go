@@ -0,0 +1,63 @@
+package procctl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Nice value range accepted by Renice.
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// signalDescriptions explains each signal in the signal menu.
+var signalDescriptions = map[string]string{
+	"HUP":  "hang up; most daemons reload their config",
+	"INT":  "interrupt, like Ctrl+C",
+	"QUIT": "quit and dump core",
+	"USR1": "user-defined 1 (log reopen, status dump…)",
+	"USR2": "user-defined 2",
+	"STOP": "pause; cannot be caught",
+	"CONT": "resume a stopped process",
+	"TERM": "terminate gracefully",
+	"KILL": "kill immediately; cannot be caught",
+	"CHLD": "child exited; nudges a parent to reap its zombies",
+}
+
+// Name returns the portable name of sig (e.g. "TERM"), or its number.
+// Remote clients send names rather than numbers because signal numbers
+// differ between operating systems.
+func (s Signal) Name() string {
+	for name, sig := range signalNames {
+		if sig == s {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d", int(s))
+}
+
+// Description explains what sig usually does.
+func (s Signal) Description() string {
+	return signalDescriptions[s.Name()]
+}
+
+// ParseSignal resolves a signal name such as "TERM" or "SIGTERM".
+func ParseSignal(name string) (Signal, error) {
+	name = strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	if sig, ok := signalNames[name]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", name)
+}
+
+// checkNice validates the arguments of Renice.
+func checkNice(pid, nice int) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to renice PID %d", pid)
+	}
+	if nice < MinNice || nice > MaxNice {
+		return fmt.Errorf("nice %d out of range %d..%d", nice, MinNice, MaxNice)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build !windows
+
+// Package procctl sends control actions (signals, priority and CPU
+// affinity changes) to local processes.
+package procctl
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// Signal represents a signal to send to a process.
+type Signal int
+
+const (
+	SIGHUP  Signal = Signal(syscall.SIGHUP)
+	SIGINT  Signal = Signal(syscall.SIGINT)
+	SIGQUIT Signal = Signal(syscall.SIGQUIT)
+	SIGUSR1 Signal = Signal(syscall.SIGUSR1)
+	SIGUSR2 Signal = Signal(syscall.SIGUSR2)
+	SIGSTOP Signal = Signal(syscall.SIGSTOP)
+	SIGCONT Signal = Signal(syscall.SIGCONT)
+	SIGTERM Signal = Signal(syscall.SIGTERM)
+	SIGKILL Signal = Signal(syscall.SIGKILL)
+	SIGCHLD Signal = Signal(syscall.SIGCHLD)
+)
+
+// Signals lists the signals that can be sent, in menu order.
+var Signals = []Signal{SIGHUP, SIGINT, SIGQUIT, SIGUSR1, SIGUSR2, SIGSTOP, SIGCONT, SIGTERM, SIGKILL, SIGCHLD}
+
+// signalNames maps portable signal names to local signal numbers.
+var signalNames = map[string]Signal{
+	"HUP":  SIGHUP,
+	"INT":  SIGINT,
+	"QUIT": SIGQUIT,
+	"USR1": SIGUSR1,
+	"USR2": SIGUSR2,
+	"STOP": SIGSTOP,
+	"CONT": SIGCONT,
+	"TERM": SIGTERM,
+	"KILL": SIGKILL,
+	"CHLD": SIGCHLD,
+}
+
+// Kill sends sig to the given PID.
+// Rejects PID <= 1 to prevent killing init or the entire process group.
+func Kill(pid int, sig Signal) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to signal PID %d", pid)
+	}
+	return syscall.Kill(pid, syscall.Signal(sig))
+}
+
+// Renice sets the nice value of pid (-20 highest to 19 lowest priority).
+// Raising priority usually requires root.
+func Renice(pid, nice int) error {
+	if err := checkNice(pid, nice); err != nil {
+		return err
+	}
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice)
+}
@@ -0,0 +1,54 @@
+//go:build windows
+
+// Package procctl sends control actions (signals, priority and CPU
+// affinity changes) to local processes.
+package procctl
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Signal represents a signal to send to a process.
+type Signal int
+
+const (
+	SIGTERM Signal = 15
+	SIGKILL Signal = 9
+)
+
+// Signals lists the signals that can be sent, in menu order. Windows has
+// no signals; both map to taskkill.
+var Signals = []Signal{SIGTERM, SIGKILL}
+
+// signalNames maps portable signal names to local signal numbers.
+var signalNames = map[string]Signal{
+	"TERM": SIGTERM,
+	"KILL": SIGKILL,
+}
+
+// Kill terminates the given PID on Windows via taskkill.
+// Rejects PID <= 1 to prevent killing critical system processes.
+func Kill(pid int, sig Signal) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to signal PID %d", pid)
+	}
+	if sig != SIGTERM && sig != SIGKILL {
+		return fmt.Errorf("signal %s is not supported on windows", sig.Name())
+	}
+	var cmd *exec.Cmd
+	if sig == SIGKILL {
+		cmd = exec.Command("taskkill", "/F", "/PID", fmt.Sprint(pid))
+	} else {
+		cmd = exec.Command("taskkill", "/PID", fmt.Sprint(pid))
+	}
+	return cmd.Run()
+}
+
+// Renice is not supported on Windows.
+func Renice(pid, nice int) error {
+	if err := checkNice(pid, nice); err != nil {
+		return err
+	}
+	return fmt.Errorf("renice is not supported on windows")
+}
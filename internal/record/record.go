@@ -0,0 +1,235 @@
+// Package record reads and writes hideTop session recordings.
+//
+// A recording is a short magic header followed by frames. Each frame is
+// a fixed-size header (the lengths of its two payloads and the time it
+// was collected), the process events that are new since the previous
+// frame as a gob, and the frame itself as a gzip-compressed gob. Frames
+// are encoded independently, so replay can index them by offset and read
+// any one of them without decoding the rest, and a recording cut short
+// by a crash or kill is still readable up to the last complete frame.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// magic identifies a recording file and its format version.
+const magic = "HTREC2\n"
+
+// headerSize is the size of a frame header: the frame and event payload
+// lengths as uint32s and the collection time as Unix nanoseconds.
+const headerSize = 16
+
+// Frame is one recorded tick: the snapshot plus the deltas the TUI
+// computed for it, so replay shows exactly what was on screen.
+type Frame struct {
+	Snapshot  metrics.Snapshot
+	NetDelta  metrics.NetworkDelta
+	DiskDelta metrics.DiskDelta
+}
+
+// Writer appends frames to a recording file.
+type Writer struct {
+	f   *os.File
+	buf bytes.Buffer
+	zw  *gzip.Writer
+
+	// last is the newest process event written so far. Snapshots carry
+	// the whole event log; only the events after it are stored.
+	last    metrics.ProcessEvent
+	hasLast bool
+}
+
+// Create creates (or truncates) path and writes the recording header.
+func Create(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(f, magic); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Writer{f: f, zw: gzip.NewWriter(io.Discard)}, nil
+}
+
+// Write appends a frame to the file in a single write.
+func (w *Writer) Write(fr Frame) error {
+	events := w.newEvents(fr.Snapshot.ProcessEvents)
+	fr.Snapshot.ProcessEvents = nil
+
+	w.buf.Reset()
+	w.buf.Write(make([]byte, headerSize))
+	if len(events) > 0 {
+		if err := gob.NewEncoder(&w.buf).Encode(events); err != nil {
+			return err
+		}
+	}
+	eventsLen := w.buf.Len() - headerSize
+
+	w.zw.Reset(&w.buf)
+	if err := gob.NewEncoder(w.zw).Encode(&fr); err != nil {
+		return err
+	}
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	frameLen := w.buf.Len() - headerSize - eventsLen
+
+	header := w.buf.Bytes()[:headerSize]
+	binary.BigEndian.PutUint32(header[0:], uint32(frameLen))
+	binary.BigEndian.PutUint32(header[4:], uint32(eventsLen))
+	binary.BigEndian.PutUint64(header[8:], uint64(fr.Snapshot.CollectedAt.UnixNano()))
+	_, err := w.f.Write(w.buf.Bytes())
+	return err
+}
+
+// newEvents returns the events of log after the newest one already
+// written, or all of log if that one has since dropped out of it.
+func (w *Writer) newEvents(log []metrics.ProcessEvent) []metrics.ProcessEvent {
+	if w.hasLast {
+		for i := len(log) - 1; i >= 0; i-- {
+			if sameEvent(log[i], w.last) {
+				log = log[i+1:]
+				break
+			}
+		}
+	}
+	if len(log) > 0 {
+		w.last, w.hasLast = log[len(log)-1], true
+	}
+	return log
+}
+
+func sameEvent(a, b metrics.ProcessEvent) bool {
+	return a.Kind == b.Kind && a.PID == b.PID && a.Time.Equal(b.Time)
+}
+
+// Close closes the file.
+func (w *Writer) Close() error {
+	return w.f.Close()
+}
+
+// Recording is a recording opened for replay. Only an index of its
+// frames and the process event log are held in memory; frames are read
+// from the file when asked for.
+type Recording struct {
+	f      *os.File
+	frames []frameIndex
+	events []metrics.ProcessEvent // every recorded event, oldest first
+}
+
+// frameIndex locates a frame in the file.
+type frameIndex struct {
+	offset    int64 // of the gzip-compressed frame
+	length    int
+	at        time.Time
+	eventsEnd int // len(Recording.events) up to and including this frame
+}
+
+// Open opens a recording, validates its header and indexes its frames.
+// A recording truncated mid-frame ends at the last complete frame.
+func Open(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recording{f: f}
+	if err := r.index(path); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// index reads every frame header and event payload, skipping over the
+// frames themselves.
+func (r *Recording) index(path string) error {
+	br := bufio.NewReader(r.f)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return fmt.Errorf("%s: not a hideTop recording", path)
+	}
+	offset := int64(len(magic))
+	for {
+		var h [headerSize]byte
+		if _, err := io.ReadFull(br, h[:]); err != nil {
+			break
+		}
+		frameLen := int(binary.BigEndian.Uint32(h[0:]))
+		eventsLen := int(binary.BigEndian.Uint32(h[4:]))
+		at := time.Unix(0, int64(binary.BigEndian.Uint64(h[8:])))
+
+		var events []metrics.ProcessEvent
+		if eventsLen > 0 {
+			data := make([]byte, eventsLen)
+			if _, err := io.ReadFull(br, data); err != nil {
+				break
+			}
+			if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&events); err != nil {
+				return fmt.Errorf("%s: frame %d: %w", path, len(r.frames), err)
+			}
+		}
+		if n, _ := br.Discard(frameLen); n < frameLen {
+			break
+		}
+		r.events = append(r.events, events...)
+		r.frames = append(r.frames, frameIndex{
+			offset:    offset + headerSize + int64(eventsLen),
+			length:    frameLen,
+			at:        at,
+			eventsEnd: len(r.events),
+		})
+		offset += headerSize + int64(eventsLen) + int64(frameLen)
+	}
+	if len(r.frames) == 0 {
+		return fmt.Errorf("%s: recording has no frames", path)
+	}
+	return nil
+}
+
+// Len returns the number of frames.
+func (r *Recording) Len() int {
+	return len(r.frames)
+}
+
+// Time returns when frame i was collected.
+func (r *Recording) Time(i int) time.Time {
+	return r.frames[i].at
+}
+
+// Frame reads frame i from the file. Its Snapshot.ProcessEvents is the
+// event log as it was at that frame.
+func (r *Recording) Frame(i int) (Frame, error) {
+	idx := r.frames[i]
+	data := make([]byte, idx.length)
+	if _, err := r.f.ReadAt(data, idx.offset); err != nil {
+		return Frame{}, fmt.Errorf("frame %d: %w", i, err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return Frame{}, fmt.Errorf("frame %d: %w", i, err)
+	}
+	var fr Frame
+	if err := gob.NewDecoder(zr).Decode(&fr); err != nil {
+		return Frame{}, fmt.Errorf("frame %d: %w", i, err)
+	}
+	start := max(idx.eventsEnd-metrics.ProcessEventLimit, 0)
+	fr.Snapshot.ProcessEvents = r.events[start:idx.eventsEnd:idx.eventsEnd]
+	return fr, nil
+}
+
+// Close closes the underlying file.
+func (r *Recording) Close() error {
+	return r.f.Close()
+}
@@ -0,0 +1,157 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/metrics/gpu"
+)
+
+func TestRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.htrec")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < 3; i++ {
+		fr := Frame{
+			Snapshot: metrics.Snapshot{
+				CollectedAt: start.Add(time.Duration(i) * time.Second),
+				CPU:         metrics.CPUStats{Total: float64(i * 10)},
+				Processes:   []metrics.ProcessInfo{{PID: int32(100 + i), Name: "worker"}},
+				Status:      metrics.CollectionStatus{"temp": {Stale: i == 1}},
+			},
+			NetDelta: metrics.NetworkDelta{Available: true, TotalInSec: float64(i)},
+		}
+		if i == 2 {
+			fr.Snapshot.GPU = &gpu.Stats{Available: true, Utilization: 42}
+		}
+		if err := w.Write(fr); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+	if r.Len() != 3 {
+		t.Fatalf("expected 3 frames, got %d", r.Len())
+	}
+	if !r.Time(2).Equal(start.Add(2 * time.Second)) {
+		t.Errorf("unexpected frame time %v", r.Time(2))
+	}
+	// Frames can be read in any order.
+	fr2, err := r.Frame(2)
+	if err != nil {
+		t.Fatalf("Frame(2): %v", err)
+	}
+	if fr2.Snapshot.GPU == nil || fr2.Snapshot.GPU.Utilization != 42 || fr2.NetDelta.TotalInSec != 2 {
+		t.Errorf("frame 2 not preserved: %+v", fr2)
+	}
+	fr1, err := r.Frame(1)
+	if err != nil {
+		t.Fatalf("Frame(1): %v", err)
+	}
+	if fr1.Snapshot.CPU.Total != 10 || !fr1.Snapshot.Status["temp"].Stale || fr1.Snapshot.Processes[0].PID != 101 {
+		t.Errorf("frame 1 not preserved: %+v", fr1.Snapshot)
+	}
+}
+
+func TestProcessEvents_StoredOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.htrec")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	event := func(pid int32) metrics.ProcessEvent {
+		return metrics.ProcessEvent{Kind: metrics.ProcessStarted, PID: pid, Time: start.Add(time.Duration(pid) * time.Second)}
+	}
+	// Each snapshot carries the whole log; the second frame adds nothing.
+	logs := [][]metrics.ProcessEvent{
+		{event(1)},
+		{event(1)},
+		{event(1), event(2), event(3)},
+	}
+	for _, log := range logs {
+		if err := w.Write(Frame{Snapshot: metrics.Snapshot{ProcessEvents: log}}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+	if len(r.events) != 3 {
+		t.Errorf("expected each event stored once, got %d", len(r.events))
+	}
+	for i, want := range logs {
+		fr, err := r.Frame(i)
+		if err != nil {
+			t.Fatalf("Frame(%d): %v", i, err)
+		}
+		got := fr.Snapshot.ProcessEvents
+		if len(got) != len(want) || got[len(got)-1].PID != want[len(want)-1].PID {
+			t.Errorf("frame %d: expected events %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestOpen_TruncatedRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.htrec")
+	w, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.Write(Frame{Snapshot: metrics.Snapshot{CPU: metrics.CPUStats{Total: 1}}}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	_ = w.Close()
+	// Simulate a crash mid-write: cut the last frame short.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("expected truncated recording to be readable, got %v", err)
+	}
+	defer r.Close()
+	if r.Len() != 2 {
+		t.Fatalf("expected 2 frames, got %d", r.Len())
+	}
+	if fr, err := r.Frame(1); err != nil || fr.Snapshot.CPU.Total != 1 {
+		t.Errorf("Frame(1) = %+v, %v", fr, err)
+	}
+}
+
+func TestOpen_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`{"CPU":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Fatalf("expected non-recording file to be rejected")
+	}
+}
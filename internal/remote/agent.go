@@ -0,0 +1,247 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/metrics"
+	"github.com/youhide/hideTop/internal/procctl"
+)
+
+// helloTimeout bounds how long a new connection may take to authenticate.
+const helloTimeout = 10 * time.Second
+
+// Agent collects snapshots and streams them to connected clients.
+type Agent struct {
+	cfg      config.Config
+	hostname string
+
+	mu      sync.Mutex
+	clients map[*agentConn]struct{}
+	latest  *metrics.Snapshot
+}
+
+// agentConn is one authenticated client. Snapshots are handed over in a
+// one-slot channel so a slow client only ever skips to the newest one.
+type agentConn struct {
+	conn      net.Conn
+	snapshots chan metrics.Snapshot
+	writeMu   sync.Mutex
+}
+
+// NewAgent returns an Agent for cfg. Call Serve to start it.
+func NewAgent(cfg config.Config) *Agent {
+	host, _ := os.Hostname()
+	return &Agent{
+		cfg:      cfg,
+		hostname: host,
+		clients:  make(map[*agentConn]struct{}),
+	}
+}
+
+// Serve listens on cfg.Listen, collects on the refresh interval and
+// streams snapshots to clients until ctx is done.
+func (a *Agent) Serve(ctx context.Context) error {
+	ln, err := net.Listen("tcp", a.cfg.Listen)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	if a.cfg.Token == "" {
+		slog.Warn("no token configured; clients can watch but not signal processes")
+	}
+	slog.Info("agent listening", "addr", ln.Addr().String())
+
+	go a.collectLoop(ctx)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			slog.Warn("accept failed", "err", err)
+			continue
+		}
+		go a.handle(ctx, conn)
+	}
+}
+
+func (a *Agent) collectLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	// Read the CPU counters ahead of the first snapshot so its CPU
+	// percentages cover a short window rather than the time since boot.
+	previous := metrics.PrimeCPU(ctx)
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(metrics.CPUPrimeWindow):
+	}
+	for {
+		cctx, cancel := context.WithTimeout(ctx, a.cfg.CollectionTimeout())
+		snap := metrics.Collect(cctx, metrics.SortByCPU, a.cfg.ProcLimit,
+			a.cfg.ProcessSampleEvery(), previous, a.cfg.CollectOptions())
+		cancel()
+		previous = snap
+		a.broadcast(snap)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) broadcast(snap metrics.Snapshot) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latest = &snap
+	for c := range a.clients {
+		offerLatest(c.snapshots, snap)
+	}
+}
+
+// offerLatest puts snap into a one-slot channel, replacing any snapshot
+// the consumer has not picked up yet.
+func offerLatest(ch chan metrics.Snapshot, snap metrics.Snapshot) {
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- snap:
+	default:
+	}
+}
+
+func (a *Agent) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	log := slog.With("client", conn.RemoteAddr().String())
+	r := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	hello, err := ReadMessage(r)
+	if err != nil {
+		log.Debug("hello failed", "err", err)
+		return
+	}
+	if hello.Type != TypeHello || hello.Version != ProtocolVersion {
+		_ = WriteMessage(conn, Message{Type: TypeError, Error: fmt.Sprintf("unsupported protocol (want version %d)", ProtocolVersion)})
+		return
+	}
+	if a.cfg.Token != "" && !tokenMatches(hello.Token, a.cfg.Token) {
+		log.Warn("rejected client with bad token")
+		_ = WriteMessage(conn, Message{Type: TypeError, Error: "authentication failed"})
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	c := &agentConn{conn: conn, snapshots: make(chan metrics.Snapshot, 1)}
+	if err := c.write(Message{Type: TypeWelcome, Version: ProtocolVersion, Hostname: a.hostname}); err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	a.clients[c] = struct{}{}
+	if a.latest != nil {
+		offerLatest(c.snapshots, *a.latest)
+	}
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.clients, c)
+		a.mu.Unlock()
+	}()
+	log.Info("client connected")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msg, err := ReadMessage(r)
+			if err != nil {
+				return
+			}
+			if msg.Type == TypeControl && msg.Control != nil {
+				res := a.control(*msg.Control)
+				log.Info("control", "action", msg.Control.Action, "pid", msg.Control.PID, "error", res.Error)
+				if err := c.write(Message{Type: TypeResult, Result: &res}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			log.Info("client disconnected")
+			return
+		case snap := <-c.snapshots:
+			if err := c.write(Message{Type: TypeSnapshot, Snapshot: &snap}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// control performs a client's control request. Control requires a
+// token to be configured on the agent; the connection has already been
+// authenticated with it.
+func (a *Agent) control(ctl Control) Result {
+	res := Result{ID: ctl.ID}
+	if a.cfg.Token == "" {
+		res.Error = "agent has no token configured; process control disabled"
+		return res
+	}
+	var err error
+	switch ctl.Action {
+	case ActionSignal:
+		var sig procctl.Signal
+		if sig, err = procctl.ParseSignal(ctl.Signal); err == nil {
+			err = procctl.Kill(int(ctl.PID), sig)
+		}
+	case ActionRenice:
+		err = procctl.Renice(int(ctl.PID), ctl.Nice)
+	case ActionAffinity:
+		err = procctl.SetAffinity(int(ctl.PID), ctl.CPUs)
+	default:
+		err = fmt.Errorf("unknown action %q", ctl.Action)
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func (c *agentConn) write(msg Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return WriteMessage(c.conn, msg)
+}
+
+func tokenMatches(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
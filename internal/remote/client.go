@@ -0,0 +1,181 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// Client receives snapshots from an agent and forwards control requests.
+type Client struct {
+	conn     net.Conn
+	addr     string
+	hostname string
+
+	snapshots chan metrics.Snapshot
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan Result
+	err     error
+}
+
+// Dial connects to the agent at addr and authenticates with token.
+func Dial(ctx context.Context, addr, token string) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+
+	_ = conn.SetDeadline(time.Now().Add(helloTimeout))
+	if err := WriteMessage(conn, Message{Type: TypeHello, Version: ProtocolVersion, Token: token}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	reply, err := ReadMessage(r)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if reply.Type != TypeWelcome {
+		_ = conn.Close()
+		if reply.Error != "" {
+			return nil, fmt.Errorf("agent %s: %s", addr, reply.Error)
+		}
+		return nil, fmt.Errorf("agent %s: unexpected %q reply", addr, reply.Type)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:      conn,
+		addr:      addr,
+		hostname:  reply.Hostname,
+		snapshots: make(chan metrics.Snapshot, 1),
+		pending:   make(map[uint64]chan Result),
+	}
+	go c.readLoop(r)
+	return c, nil
+}
+
+// Addr returns the agent address the client dialled.
+func (c *Client) Addr() string { return c.addr }
+
+// Hostname returns the hostname reported by the agent.
+func (c *Client) Hostname() string { return c.hostname }
+
+// Snapshots delivers snapshots as they arrive, skipping ahead to the
+// newest one if the consumer falls behind. It is closed when the
+// connection ends; Err then reports why.
+func (c *Client) Snapshots() <-chan metrics.Snapshot { return c.snapshots }
+
+// Err returns the error that ended the connection, if any.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection.
+func (c *Client) Close() error { return c.conn.Close() }
+
+// Signal asks the agent to send the named signal (e.g. "TERM") to pid.
+func (c *Client) Signal(ctx context.Context, pid int32, signal string) error {
+	return c.control(ctx, Control{Action: ActionSignal, PID: pid, Signal: signal})
+}
+
+// Renice asks the agent to set the nice value of pid.
+func (c *Client) Renice(ctx context.Context, pid int32, nice int) error {
+	return c.control(ctx, Control{Action: ActionRenice, PID: pid, Nice: nice})
+}
+
+// SetAffinity asks the agent to restrict pid to the given CPUs.
+func (c *Client) SetAffinity(ctx context.Context, pid int32, cpus []int) error {
+	return c.control(ctx, Control{Action: ActionAffinity, PID: pid, CPUs: cpus})
+}
+
+func (c *Client) control(ctx context.Context, ctl Control) error {
+	ch := make(chan Result, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.nextID++
+	ctl.ID = c.nextID
+	c.pending[ctl.ID] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, ctl.ID)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err := WriteMessage(c.conn, Message{Type: TypeControl, Control: &ctl})
+	c.writeMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return c.Err()
+		}
+		if res.Error != "" {
+			return errors.New(res.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) readLoop(r *bufio.Reader) {
+	var err error
+	for {
+		var msg Message
+		msg, err = ReadMessage(r)
+		if err != nil {
+			break
+		}
+		switch msg.Type {
+		case TypeSnapshot:
+			if msg.Snapshot != nil {
+				offerLatest(c.snapshots, *msg.Snapshot)
+			}
+		case TypeResult:
+			if msg.Result != nil {
+				c.mu.Lock()
+				if ch, ok := c.pending[msg.Result.ID]; ok {
+					ch <- *msg.Result
+				}
+				c.mu.Unlock()
+			}
+		case TypeError:
+			err = fmt.Errorf("agent: %s", msg.Error)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("connection to %s lost: %w", c.addr, err)
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	close(c.snapshots)
+}
@@ -0,0 +1,110 @@
+// Package remote splits collection from presentation: an agent collects
+// snapshots and streams them to TUI clients over TCP.
+//
+// The wire protocol is a sequence of frames, each a 4-byte big-endian
+// payload length followed by a JSON-encoded Message. A client opens with
+// a hello carrying the shared token; the agent answers with welcome or
+// error and then streams snapshot messages. Clients may send control
+// messages at any time, which the agent answers with a result carrying
+// the same ID.
+package remote
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// ProtocolVersion is bumped on incompatible wire changes.
+const ProtocolVersion = 1
+
+// maxFrameSize bounds a single frame so a corrupt or hostile peer cannot
+// make the reader allocate unbounded memory.
+const maxFrameSize = 16 << 20
+
+// Message types.
+const (
+	TypeHello    = "hello"
+	TypeWelcome  = "welcome"
+	TypeError    = "error"
+	TypeSnapshot = "snapshot"
+	TypeControl  = "control"
+	TypeResult   = "result"
+)
+
+// Control actions.
+const (
+	ActionSignal   = "signal"
+	ActionRenice   = "renice"
+	ActionAffinity = "affinity"
+)
+
+// Message is the envelope for every frame.
+type Message struct {
+	Type     string            `json:"type"`
+	Version  int               `json:"version,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	Hostname string            `json:"hostname,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Snapshot *metrics.Snapshot `json:"snapshot,omitempty"`
+	Control  *Control          `json:"control,omitempty"`
+	Result   *Result           `json:"result,omitempty"`
+}
+
+// Control asks the agent to act on one of its processes.
+type Control struct {
+	ID     uint64 `json:"id"`
+	Action string `json:"action"`
+	PID    int32  `json:"pid"`
+	Signal string `json:"signal,omitempty"` // portable name, e.g. "TERM"
+	Nice   int    `json:"nice,omitempty"`   // ActionRenice
+	CPUs   []int  `json:"cpus,omitempty"`   // ActionAffinity
+}
+
+// Result answers a Control with the same ID. Error is empty on success.
+type Result struct {
+	ID    uint64 `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// WriteMessage writes msg as a single frame.
+func WriteMessage(w io.Writer, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxFrameSize {
+		return fmt.Errorf("frame too large: %d bytes", len(payload))
+	}
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(len(payload)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
+
+// ReadMessage reads a single frame.
+func ReadMessage(r io.Reader) (Message, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return Message{}, err
+	}
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n > maxFrameSize {
+		return Message{}, fmt.Errorf("frame too large: %d bytes", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Message{}, err
+	}
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return Message{}, fmt.Errorf("decode frame: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	snap := metrics.Snapshot{
+		CPU:       metrics.CPUStats{Total: 12.5},
+		Processes: []metrics.ProcessInfo{{PID: 7, Name: "sshd"}},
+	}
+	if err := WriteMessage(&buf, Message{Type: TypeSnapshot, Snapshot: &snap}); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	msg, err := ReadMessage(&buf)
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if msg.Type != TypeSnapshot || msg.Snapshot == nil || msg.Snapshot.CPU.Total != 12.5 || msg.Snapshot.Processes[0].Name != "sshd" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+}
+
+func TestReadMessage_RejectsOversizedFrame(t *testing.T) {
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], maxFrameSize+1)
+	if _, err := ReadMessage(bytes.NewReader(hdr[:])); err == nil {
+		t.Fatalf("expected oversized frame to be rejected")
+	}
+}
+
+// pipeClient connects a fake client to a.handle over an in-memory pipe
+// and performs the hello exchange.
+func pipeClient(t *testing.T, ctx context.Context, a *Agent, token string) (net.Conn, *bufio.Reader, Message) {
+	t.Helper()
+	server, client := net.Pipe()
+	go a.handle(ctx, server)
+	t.Cleanup(func() { client.Close() })
+
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := WriteMessage(client, Message{Type: TypeHello, Version: ProtocolVersion, Token: token}); err != nil {
+		t.Fatalf("hello: %v", err)
+	}
+	r := bufio.NewReader(client)
+	reply, err := ReadMessage(r)
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	return client, r, reply
+}
+
+func TestAgent_RejectsBadToken(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := NewAgent(config.Config{Token: "s3cret"})
+
+	_, _, reply := pipeClient(t, ctx, a, "guess")
+	if reply.Type != TypeError || !strings.Contains(reply.Error, "authentication") {
+		t.Fatalf("expected authentication error, got %+v", reply)
+	}
+}
+
+func TestAgent_StreamsSnapshotsAndAnswersControl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := NewAgent(config.Config{Token: "s3cret"})
+	a.broadcast(metrics.Snapshot{CPU: metrics.CPUStats{Total: 1}})
+
+	conn, r, reply := pipeClient(t, ctx, a, "s3cret")
+	if reply.Type != TypeWelcome {
+		t.Fatalf("expected welcome, got %+v", reply)
+	}
+
+	// The latest snapshot is delivered immediately on connect.
+	msg, err := ReadMessage(r)
+	if err != nil || msg.Type != TypeSnapshot || msg.Snapshot.CPU.Total != 1 {
+		t.Fatalf("expected initial snapshot, got %+v (%v)", msg, err)
+	}
+
+	ctl := Control{ID: 9, Action: ActionSignal, PID: 123, Signal: "BOGUS"}
+	if err := WriteMessage(conn, Message{Type: TypeControl, Control: &ctl}); err != nil {
+		t.Fatalf("control: %v", err)
+	}
+	msg, err = ReadMessage(r)
+	if err != nil || msg.Type != TypeResult || msg.Result.ID != 9 || !strings.Contains(msg.Result.Error, "unknown signal") {
+		t.Fatalf("expected unknown signal result, got %+v (%v)", msg, err)
+	}
+}
+
+func TestAgent_ControlDisabledWithoutToken(t *testing.T) {
+	a := NewAgent(config.Config{})
+	res := a.control(Control{ID: 1, Action: ActionSignal, PID: 123, Signal: "TERM"})
+	if res.Error == "" {
+		t.Fatalf("expected control to be refused when no token is configured")
+	}
+}
+
+func TestAgent_ControlValidatesRenice(t *testing.T) {
+	a := NewAgent(config.Config{Token: "s3cret"})
+	res := a.control(Control{ID: 2, Action: ActionRenice, PID: 123, Nice: 40})
+	if res.ID != 2 || !strings.Contains(res.Error, "out of range") {
+		t.Fatalf("expected out of range error, got %+v", res)
+	}
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/youhide/hideTop/internal/alert"
+)
+
+// RenderAlertBadge renders the header badge for firing alerts, or "" if
+// none are firing.
+func RenderAlertBadge(firing int) string {
+	if firing == 0 {
+		return ""
+	}
+	label := "1 alert"
+	if firing > 1 {
+		label = fmt.Sprintf("%d alerts", firing)
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Render("⚠ " + label)
+}
+
+// RenderAlertsOverlay renders a full-screen overlay listing firing
+// alerts and recent alert events.
+func RenderAlertsOverlay(firing []alert.Alert, history []alert.Event, rules int, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render("Alerts"))
+	b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %d rules", rules)))
+	b.WriteString("\n\n")
+
+	b.WriteString(HeaderStyle.Render("Firing"))
+	b.WriteString("\n")
+	if len(firing) == 0 {
+		b.WriteString(SubtleStyle.Render("  none") + "\n")
+	}
+	red := lipgloss.NewStyle().Bold(true).Foreground(ColorRed)
+	for _, a := range firing {
+		detail := "not running"
+		if a.Metric != "" {
+			detail = fmt.Sprintf("%s = %.4g", a.Metric, a.Value)
+		}
+		b.WriteString(fmt.Sprintf("  %s  %s\n",
+			red.Render(a.Name),
+			SubtleStyle.Render(fmt.Sprintf("%s, since %s", detail, a.Since.Format("15:04:05"))),
+		))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(HeaderStyle.Render("Recent"))
+	b.WriteString("\n")
+	if len(history) == 0 {
+		b.WriteString(SubtleStyle.Render("  none") + "\n")
+	}
+	// Newest first, limited to what fits.
+	maxRows := height - 14 - len(firing)
+	if maxRows < 3 {
+		maxRows = 3
+	}
+	for i := len(history) - 1; i >= 0 && len(history)-i <= maxRows; i-- {
+		ev := history[i]
+		label := fmt.Sprintf("%-8s", ev.Status)
+		status := GreenStyle.Render(label)
+		if ev.Status == alert.StatusFiring {
+			status = red.Render(label)
+		}
+		b.WriteString(fmt.Sprintf("  %s  %s  %s\n",
+			SubtleStyle.Render(ev.At.Format("15:04:05")),
+			status,
+			ev.Name,
+		))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SubtleStyle.Render("  Press a or Esc to close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Width(width - 4).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,360 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// noSort marks columns that do not correspond to a sort field.
+const noSort = metrics.SortField(-1)
+
+// Column is a process table column.
+type Column struct {
+	ID       string // config / CLI name
+	Title    string
+	Desc     string // shown in the column picker
+	Width    int    // minimum width
+	MaxWidth int    // cap for flex columns; 0 = unlimited
+	Flex     bool   // grows to absorb spare width
+	Align    lipgloss.Position
+	Sort     metrics.SortField
+	Detail   bool // shows a ProcessDetails field, read only for shown rows
+
+	// value returns the cell text and an optional colour ("" = default).
+	value func(dp displayProc) (string, lipgloss.Color)
+}
+
+// columns is the registry of process table columns, in picker order.
+var columns = []Column{
+	{ID: "pid", Title: "PID", Desc: "Process ID", Width: 7, Sort: metrics.SortByPID,
+		value: func(dp displayProc) (string, lipgloss.Color) { return fmt.Sprintf("%d", dp.proc.PID), "" }},
+	{ID: "state", Title: "S", Desc: "State (R/S/D/Z/T)", Width: 2, Sort: metrics.SortByState,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			return metrics.StateLetter(dp.proc.State), stateColor(dp.proc.State)
+		}},
+	{ID: "user", Title: "USER", Desc: "Owner", Width: 10, Sort: metrics.SortByUser,
+		value: func(dp displayProc) (string, lipgloss.Color) { return dp.proc.User, "" }},
+	{ID: "name", Title: "NAME", Desc: "Process name (tree-indented)", Width: 20, MaxWidth: 32, Flex: true, Sort: metrics.SortByName,
+		value: func(dp displayProc) (string, lipgloss.Color) { return dp.proc.Name, "" }},
+	{ID: "threads", Title: "THR", Desc: "Thread count", Width: 4, Align: lipgloss.Right, Sort: metrics.SortByThreads,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			return countCell(dp.proc.NumThreads), ColorSubtle
+		}},
+	{ID: "cpu", Title: "CPU%", Desc: "CPU utilisation", Width: 8, Align: lipgloss.Right, Sort: metrics.SortByCPU,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			return fmt.Sprintf("%.1f", dp.proc.CPUPercent), BarColor(dp.proc.CPUPercent)
+		}},
+	{ID: "mem", Title: "MEM%", Desc: "Memory utilisation", Width: 8, Align: lipgloss.Right, Sort: metrics.SortByMem,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			return fmt.Sprintf("%.1f", dp.proc.MemPercent), BarColor(float64(dp.proc.MemPercent))
+		}},
+	{ID: "rss", Title: "RSS", Desc: "Resident memory", Width: 8, Align: lipgloss.Right, Sort: metrics.SortByRSS,
+		value: func(dp displayProc) (string, lipgloss.Color) { return compactBytes(float64(dp.proc.RSS)), "" }},
+	{ID: "vsz", Title: "VSZ", Desc: "Virtual memory", Width: 8, Align: lipgloss.Right, Sort: noSort,
+		value: func(dp displayProc) (string, lipgloss.Color) { return compactBytes(float64(dp.proc.VMS)), ColorSubtle }},
+	{ID: "nice", Title: "NI", Desc: "Nice value", Width: 3, Align: lipgloss.Right, Sort: noSort, Detail: true,
+		value: func(dp displayProc) (string, lipgloss.Color) { return fmt.Sprintf("%d", dp.proc.Nice), "" }},
+	{ID: "prio", Title: "PRI", Desc: "Kernel priority (Linux)", Width: 4, Align: lipgloss.Right, Sort: noSort, Detail: true,
+		value: func(dp displayProc) (string, lipgloss.Color) { return fmt.Sprintf("%d", dp.proc.Priority), ColorSubtle }},
+	{ID: "start", Title: "START", Desc: "Start time", Width: 6, Align: lipgloss.Right, Sort: metrics.SortByStart,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			return startCell(dp.proc.CreateTime, dp.now), ColorSubtle
+		}},
+	{ID: "time", Title: "TIME", Desc: "CPU time (user + system)", Width: 9, Align: lipgloss.Right, Sort: noSort, Detail: true,
+		value: func(dp displayProc) (string, lipgloss.Color) { return cpuTimeCell(dp.proc.CPUTime), "" }},
+	{ID: "fds", Title: "FDS", Desc: "Open file descriptors", Width: 5, Align: lipgloss.Right, Sort: noSort, Detail: true,
+		value: func(dp displayProc) (string, lipgloss.Color) { return countCell(dp.proc.NumFDs), "" }},
+	{ID: "io_r", Title: "READ/s", Desc: "Disk read rate", Width: 9, Align: lipgloss.Right, Sort: metrics.SortByIO,
+		value: func(dp displayProc) (string, lipgloss.Color) { return rateCell(dp.proc.ReadRate), ColorGreen }},
+	{ID: "io_w", Title: "WRITE/s", Desc: "Disk write rate", Width: 9, Align: lipgloss.Right, Sort: metrics.SortByIO,
+		value: func(dp displayProc) (string, lipgloss.Color) { return rateCell(dp.proc.WriteRate), ColorYellow }},
+	{ID: "cmd", Title: "COMMAND", Desc: "Full command line", Width: 20, Flex: true, Sort: noSort, Detail: true,
+		value: func(dp displayProc) (string, lipgloss.Color) {
+			if dp.proc.Cmdline == "" {
+				return "[" + dp.proc.Name + "]", ColorSubtle
+			}
+			return singleLine(dp.proc.Cmdline), ColorSubtle
+		}},
+}
+
+// DefaultColumns is the column set used when none is configured.
+var DefaultColumns = []string{"pid", "state", "user", "name", "threads", "cpu", "mem", "io_r", "io_w"}
+
+// Columns returns the registered columns in picker order.
+func Columns() []Column {
+	return columns
+}
+
+// NeedsDetails reports whether any of the column ids shows a
+// ProcessDetails field.
+func NeedsDetails(ids []string) bool {
+	for _, id := range ids {
+		if c, ok := columnByID(id); ok && c.Detail {
+			return true
+		}
+	}
+	return false
+}
+
+func columnByID(id string) (Column, bool) {
+	for _, c := range columns {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// ParseColumns validates a configured column list, normalising case and
+// whitespace. Unknown or repeated columns are an error.
+func ParseColumns(ids []string) ([]string, error) {
+	out := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+		if _, ok := columnByID(id); !ok {
+			known := make([]string, len(columns))
+			for i, c := range columns {
+				known[i] = c.ID
+			}
+			return nil, fmt.Errorf("unknown column %q (known: %s)", id, strings.Join(known, ", "))
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("column %q listed twice", id)
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return out, nil
+}
+
+// layoutCol is a column with its resolved width.
+type layoutCol struct {
+	Column
+	w int
+}
+
+// layoutColumns resolves ids to columns fitting avail cells (including
+// the two-cell row indent and one-cell separators). Columns that do not
+// fit are dropped from the right; spare width goes to flex columns.
+func layoutColumns(ids []string, avail int) []layoutCol {
+	if len(ids) == 0 {
+		ids = DefaultColumns
+	}
+	var cols []layoutCol
+	for _, id := range ids {
+		if c, ok := columnByID(id); ok {
+			cols = append(cols, layoutCol{Column: c, w: c.Width})
+		}
+	}
+
+	used := func() int {
+		total := 2 // row indent
+		for i, c := range cols {
+			if i > 0 {
+				total++
+			}
+			total += c.w
+		}
+		return total
+	}
+	for len(cols) > 1 && used() > avail {
+		cols = cols[:len(cols)-1]
+	}
+
+	// Hand spare width to flex columns, left to right, respecting caps.
+	spare := avail - used()
+	for pass := 0; pass < 2 && spare > 0; pass++ {
+		var flex []int
+		for i, c := range cols {
+			if c.Flex && (c.MaxWidth == 0 || c.w < c.MaxWidth) {
+				flex = append(flex, i)
+			}
+		}
+		if len(flex) == 0 {
+			break
+		}
+		share := spare / len(flex)
+		for n, i := range flex {
+			grow := share
+			if n == len(flex)-1 {
+				grow = spare
+			}
+			if limit := cols[i].MaxWidth; limit > 0 && cols[i].w+grow > limit {
+				grow = limit - cols[i].w
+			}
+			cols[i].w += grow
+			spare -= grow
+		}
+	}
+	return cols
+}
+
+// renderCell renders one cell padded or truncated to w.
+func renderCell(text string, w int, align lipgloss.Position, color lipgloss.Color) string {
+	style := lipgloss.NewStyle().Width(w).Align(align)
+	if color != "" {
+		style = style.Foreground(color)
+	}
+	return style.Render(truncateRunes(text, w))
+}
+
+// compactBytes formats a byte count in at most 7 cells, e.g. "512.0M".
+func compactBytes(b float64) string {
+	switch {
+	case b <= 0:
+		return ""
+	case b >= 1<<40:
+		return fmt.Sprintf("%.1fT", b/(1<<40))
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1fG", b/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1fM", b/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1fK", b/(1<<10))
+	default:
+		return fmt.Sprintf("%.0fB", b)
+	}
+}
+
+// singleLine replaces control characters (e.g. newlines in an inline
+// script argument) with spaces so a cell never breaks the row.
+func singleLine(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// rateCell formats a bytes/s rate, blank when idle.
+func rateCell(rate float64) string {
+	return compactBytes(rate)
+}
+
+// countCell formats a count, blank when zero (unknown).
+func countCell(n int32) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// startCell formats a start time like ps: clock time for processes
+// started today, otherwise the date.
+func startCell(createMillis int64, now time.Time) string {
+	if createMillis <= 0 {
+		return ""
+	}
+	t := time.UnixMilli(createMillis).In(now.Location())
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04")
+	}
+	if t.Year() == now.Year() {
+		return t.Format("Jan02")
+	}
+	return t.Format("2006")
+}
+
+// cpuTimeCell formats CPU seconds as m:ss.cc, or h:mm:ss past an hour.
+func cpuTimeCell(secs float64) string {
+	if secs <= 0 {
+		return ""
+	}
+	if secs >= 3600 {
+		s := int(secs)
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	m := int(secs) / 60
+	return fmt.Sprintf("%d:%05.2f", m, secs-float64(m*60))
+}
+
+// ColumnPickerItem is one row of the column picker.
+type ColumnPickerItem struct {
+	Column
+	Enabled bool
+}
+
+// ColumnPickerItems lists enabled columns in display order followed by
+// the remaining columns in registry order.
+func ColumnPickerItems(enabled []string) []ColumnPickerItem {
+	var items []ColumnPickerItem
+	on := make(map[string]bool, len(enabled))
+	for _, id := range enabled {
+		if c, ok := columnByID(id); ok && !on[id] {
+			on[id] = true
+			items = append(items, ColumnPickerItem{Column: c, Enabled: true})
+		}
+	}
+	for _, c := range columns {
+		if !on[c.ID] {
+			items = append(items, ColumnPickerItem{Column: c})
+		}
+	}
+	return items
+}
+
+// RenderColumnPicker renders the column picker overlay.
+func RenderColumnPicker(items []ColumnPickerItem, cursor, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render("Process Columns"))
+	b.WriteString("\n\n")
+
+	for i, it := range items {
+		check := SubtleStyle.Render("[ ]")
+		if it.Enabled {
+			check = GreenStyle.Render("[x]")
+		}
+		line := fmt.Sprintf("%s %s  %s",
+			check,
+			lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Width(8).Render(it.ID),
+			SubtleStyle.Render(it.Desc),
+		)
+		if i == cursor {
+			line = lipgloss.NewStyle().Background(ColorSelectedBg).Render("▎" + line)
+		} else {
+			line = " " + line
+		}
+		b.WriteString(" " + line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SubtleStyle.Render("  Space toggle  │  J/K move down/up  │  r reset  │  C or Esc close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// SortFields returns the distinct sort fields of the given columns, in
+// column order.
+func SortFields(ids []string) []metrics.SortField {
+	if len(ids) == 0 {
+		ids = DefaultColumns
+	}
+	var fields []metrics.SortField
+	for _, id := range ids {
+		c, ok := columnByID(id)
+		if ok && c.Sort != noSort && !slices.Contains(fields, c.Sort) {
+			fields = append(fields, c.Sort)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func layoutIDs(cols []layoutCol) []string {
+	ids := make([]string, len(cols))
+	for i, c := range cols {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestLayoutColumns_DropsFromRightWhenNarrow(t *testing.T) {
+	// 2 indent + pid 7 + 1 + name 20 = 30 cells
+	cols := layoutColumns([]string{"pid", "name", "cpu"}, 30)
+	if got := layoutIDs(cols); len(got) != 2 || got[1] != "name" {
+		t.Fatalf("expected cpu to be dropped, got %v", got)
+	}
+	if cols[1].w != 20 {
+		t.Errorf("expected name at its minimum width, got %d", cols[1].w)
+	}
+}
+
+func TestLayoutColumns_FlexAbsorbsSpare(t *testing.T) {
+	cols := layoutColumns([]string{"pid", "name", "cmd"}, 100)
+	total := 2
+	for i, c := range cols {
+		if i > 0 {
+			total++
+		}
+		total += c.w
+	}
+	if total != 100 {
+		t.Errorf("expected columns to fill 100 cells, got %d", total)
+	}
+	if cols[1].w != 32 {
+		t.Errorf("expected name capped at 32, got %d", cols[1].w)
+	}
+	if cols[2].w <= 20 {
+		t.Errorf("expected cmd to grow, got %d", cols[2].w)
+	}
+}
+
+func TestLayoutColumns_DefaultsAndUnknown(t *testing.T) {
+	if got := layoutIDs(layoutColumns(nil, 200)); len(got) != len(DefaultColumns) {
+		t.Errorf("expected default columns, got %v", got)
+	}
+	if got := layoutIDs(layoutColumns([]string{"pid", "bogus"}, 200)); len(got) != 1 {
+		t.Errorf("expected unknown columns to be skipped, got %v", got)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	got, err := ParseColumns([]string{" PID", "user", "io_r"})
+	if err != nil || len(got) != 3 || got[0] != "pid" {
+		t.Fatalf("ParseColumns = %v, %v", got, err)
+	}
+	for _, bad := range [][]string{{"pid", "bogus"}, {"pid", "pid"}, {}} {
+		if _, err := ParseColumns(bad); err == nil {
+			t.Errorf("ParseColumns(%v): expected error", bad)
+		}
+	}
+}
+
+func TestCellFormatters(t *testing.T) {
+	if got := cpuTimeCell(75.5); got != "1:15.50" {
+		t.Errorf("cpuTimeCell(75.5) = %q", got)
+	}
+	if got := cpuTimeCell(3725); got != "1:02:05" {
+		t.Errorf("cpuTimeCell(3725) = %q", got)
+	}
+	now := time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)
+	if got := startCell(time.Date(2024, 3, 10, 9, 5, 0, 0, time.UTC).UnixMilli(), now); got != "09:05" {
+		t.Errorf("startCell(today) = %q", got)
+	}
+	if got := startCell(time.Date(2024, 1, 2, 9, 5, 0, 0, time.UTC).UnixMilli(), now); got != "Jan02" {
+		t.Errorf("startCell(this year) = %q", got)
+	}
+	if got := compactBytes(1536 * 1024); got != "1.5M" {
+		t.Errorf("compactBytes = %q", got)
+	}
+}
+
+func TestSingleLine(t *testing.T) {
+	if got := singleLine("python3 -c\nimport os\tx"); got != "python3 -c import os x" {
+		t.Errorf("singleLine = %q", got)
+	}
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderConfirmList renders a y/N confirmation overlay for an action
+// on several processes, listing them (e.g. "1234  node"). Items that do
+// not fit are summarised as "+N more".
+func RenderConfirmList(title string, items []string, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render(title))
+	b.WriteString("\n\n")
+
+	limit := max(height-10, 1)
+	for i, it := range items {
+		if i == limit && len(items) > limit+1 {
+			b.WriteString(SubtleStyle.Render(fmt.Sprintf("  +%d more", len(items)-limit)) + "\n")
+			break
+		}
+		b.WriteString("  " + it + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Render("  y confirm") +
+		SubtleStyle.Render("  │  any other key cancels"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorRed).
+		Padding(1, 2).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -14,39 +15,68 @@ func RenderCPU(cpu metrics.CPUStats, width int, history []float64) string {
 	b.WriteString(HeaderStyle.Render("CPU"))
 	n := len(cpu.PerCore)
 	if n > 0 {
-		b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %d cores", n)))
+		b.WriteString(SubtleStyle.Render("  " + cpuSummary(cpu)))
 	}
 	b.WriteByte('\n')
 
+	// Stacked bars by CPU state, like htop's detailed CPU meters, when
+	// the breakdown is available.
+	detailed := n > 0 && len(cpu.PerCoreTimes) == n
+	bar := func(pct float64, t metrics.CPUTimes, label string, w int) string {
+		if detailed {
+			return renderStackedBar(cpuSegments(t), label, w)
+		}
+		return renderBar(pct, label, w)
+	}
+
 	totalLabel := fmt.Sprintf("TOTAL %5.1f%%", cpu.Total)
-	b.WriteString(lipgloss.NewStyle().Bold(true).Render(renderBar(cpu.Total, totalLabel, width-4)))
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(bar(cpu.Total, cpu.Times, totalLabel, width-4)))
 	b.WriteByte('\n')
+	if detailed {
+		b.WriteString(cpuLegend(cpu.Times, width-4))
+		b.WriteByte('\n')
+	}
 
-	// Two-column layout: left = cpu0..cpu4, right = cpu5..cpu9
-	half := (n + 1) / 2
 	colWidth := (width - 6) / 2
-
-	var leftCol, rightCol strings.Builder
-	for i := 0; i < half; i++ {
+	coreBar := func(i int) string {
 		label := fmt.Sprintf("cpu%-2d %5.1f%%", i, cpu.PerCore[i])
-		leftCol.WriteString(renderBar(cpu.PerCore[i], label, colWidth))
-		if i < half-1 {
-			leftCol.WriteByte('\n')
+		if i < len(cpu.Cores) {
+			label = fmt.Sprintf("cpu%-2d %5.1f%%", cpu.Cores[i].CPU, cpu.PerCore[i])
+			if cpu.Cores[i].MHz > 0 {
+				label += fmt.Sprintf(" %4.0f", cpu.Cores[i].MHz)
+			}
 		}
+		return bar(cpu.PerCore[i], coreTimes(cpu, i), label, colWidth)
 	}
-	for i := half; i < n; i++ {
-		label := fmt.Sprintf("cpu%-2d %5.1f%%", i, cpu.PerCore[i])
-		rightCol.WriteString(renderBar(cpu.PerCore[i], label, colWidth))
-		if i < n-1 {
-			rightCol.WriteByte('\n')
+
+	// One block of bars per socket and core type, each in two columns:
+	// the first half of its CPUs on the left, the rest on the right.
+	groups := cpuGroups(cpu)
+	for g, group := range groups {
+		if group.title != "" {
+			b.WriteString(SubtleStyle.Render(group.title))
+			b.WriteByte('\n')
+		}
+		half := (len(group.cpus) + 1) / 2
+		var leftCol, rightCol strings.Builder
+		for j, i := range group.cpus {
+			col := &leftCol
+			if j >= half {
+				col = &rightCol
+			}
+			if col.Len() > 0 {
+				col.WriteByte('\n')
+			}
+			col.WriteString(coreBar(i))
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			leftCol.String(), "  ", rightCol.String(),
+		))
+		if g < len(groups)-1 {
+			b.WriteByte('\n')
 		}
 	}
 
-	cols := lipgloss.JoinHorizontal(lipgloss.Top,
-		leftCol.String(), "  ", rightCol.String(),
-	)
-	b.WriteString(cols)
-
 	// Sparkline history
 	if len(history) > 1 {
 		b.WriteByte('\n')
@@ -56,6 +86,121 @@ func RenderCPU(cpu metrics.CPUStats, width int, history []float64) string {
 	return PanelStyle.Width(width - 2).Render(b.String())
 }
 
+// cpuSummary describes the CPU for the panel header: "8 cores" without
+// topology, else e.g. "16 cores · 24 threads · 8P + 8E · powersave ·
+// avg 3120 MHz".
+func cpuSummary(cpu metrics.CPUStats) string {
+	if len(cpu.Cores) != len(cpu.PerCore) {
+		return fmt.Sprintf("%d cores", len(cpu.PerCore))
+	}
+	type coreKey struct{ pkg, core int }
+	physical := make(map[coreKey]string)
+	packages := make(map[int]bool)
+	governors := make(map[string]bool)
+	var mhz float64
+	for _, c := range cpu.Cores {
+		physical[coreKey{c.Package, c.Core}] = c.Type
+		packages[c.Package] = true
+		governors[c.Governor] = true
+		mhz += c.MHz
+	}
+
+	parts := []string{fmt.Sprintf("%d cores", len(physical))}
+	if len(physical) != len(cpu.Cores) {
+		parts = append(parts, fmt.Sprintf("%d threads", len(cpu.Cores)))
+	}
+	var p, e int
+	for _, typ := range physical {
+		switch typ {
+		case metrics.CoreTypePerformance:
+			p++
+		case metrics.CoreTypeEfficiency:
+			e++
+		}
+	}
+	if p > 0 && e > 0 {
+		parts = append(parts, fmt.Sprintf("%dP + %dE", p, e))
+	}
+	if len(packages) > 1 {
+		parts = append(parts, fmt.Sprintf("%d packages", len(packages)))
+	}
+	if len(governors) == 1 && cpu.Cores[0].Governor != "" {
+		parts = append(parts, cpu.Cores[0].Governor)
+	}
+	if mhz > 0 {
+		parts = append(parts, fmt.Sprintf("avg %.0f MHz", mhz/float64(len(cpu.Cores))))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// cpuGroup is a block of per-core bars under an optional title.
+type cpuGroup struct {
+	title string
+	cpus  []int // indexes into CPUStats.PerCore
+}
+
+// cpuGroups splits the cores by package and, on hybrid CPUs, core type,
+// performance cores first. Without topology, or with a single package
+// of one core type, all cores form one untitled group.
+func cpuGroups(cpu metrics.CPUStats) []cpuGroup {
+	all := make([]int, len(cpu.PerCore))
+	for i := range all {
+		all[i] = i
+	}
+	if len(cpu.Cores) != len(cpu.PerCore) {
+		return []cpuGroup{{cpus: all}}
+	}
+
+	type groupKey struct {
+		pkg int
+		typ string
+	}
+	var keys []groupKey
+	members := make(map[groupKey][]int)
+	packages := make(map[int]bool)
+	for i, c := range cpu.Cores {
+		k := groupKey{c.Package, c.Type}
+		if _, ok := members[k]; !ok {
+			keys = append(keys, k)
+		}
+		members[k] = append(members[k], i)
+		packages[c.Package] = true
+	}
+	if len(keys) == 1 {
+		return []cpuGroup{{cpus: all}}
+	}
+	slices.SortFunc(keys, func(a, b groupKey) int {
+		if a.pkg != b.pkg {
+			return a.pkg - b.pkg
+		}
+		return strings.Compare(b.typ, a.typ) // "P" before "E"
+	})
+
+	groups := make([]cpuGroup, 0, len(keys))
+	for _, k := range keys {
+		var title []string
+		if len(packages) > 1 {
+			title = append(title, fmt.Sprintf("package %d", k.pkg))
+		}
+		switch k.typ {
+		case metrics.CoreTypePerformance:
+			title = append(title, "performance cores")
+		case metrics.CoreTypeEfficiency:
+			title = append(title, "efficiency cores")
+		}
+		cpus := members[k]
+		var maxMHz float64
+		for _, i := range cpus {
+			maxMHz = max(maxMHz, cpu.Cores[i].MaxMHz)
+		}
+		if maxMHz > 0 {
+			title = append(title, fmt.Sprintf("max %.0f MHz", maxMHz))
+		}
+		groups = append(groups, cpuGroup{title: strings.Join(title, " · "), cpus: cpus})
+	}
+	return groups
+}
+
 func renderBar(pct float64, label string, maxWidth int) string {
 	if maxWidth < 1 {
 		maxWidth = 1
@@ -84,3 +229,82 @@ func renderBar(pct float64, label string, maxWidth int) string {
 		emptyStyle.Render(strings.Repeat("░", empty)),
 	)
 }
+
+// coreTimes returns core i's CPU time breakdown, if any.
+func coreTimes(cpu metrics.CPUStats, i int) metrics.CPUTimes {
+	if i < len(cpu.PerCoreTimes) {
+		return cpu.PerCoreTimes[i]
+	}
+	return metrics.CPUTimes{}
+}
+
+// barSegment is one run of a stacked bar, such as a CPU state with
+// top's abbreviation.
+type barSegment struct {
+	abbr  string
+	pct   float64
+	color lipgloss.Color
+}
+
+// cpuSegments returns t's busy states and I/O wait in the order htop
+// stacks them.
+func cpuSegments(t metrics.CPUTimes) []barSegment {
+	return []barSegment{
+		{"us", t.User, ColorGreen},
+		{"ni", t.Nice, ColorTitle},
+		{"sy", t.System, ColorRed},
+		{"hi", t.IRQ, ColorYellow},
+		{"si", t.SoftIRQ, ColorMagenta},
+		{"st", t.Steal, ColorCyan},
+		{"gu", t.Guest, ColorHeader},
+		{"wa", t.IOWait, ColorSubtle},
+	}
+}
+
+// cpuLegend renders the breakdown as "us 12.0 sy 3.1 wa 0.4 …" in the
+// segment colours. User and system are always listed, the other states
+// only while non-zero, as many as fit in width.
+func cpuLegend(t metrics.CPUTimes, width int) string {
+	var parts []string
+	used := 0
+	for _, seg := range cpuSegments(t) {
+		if seg.pct < 0.05 && seg.abbr != "us" && seg.abbr != "sy" {
+			continue
+		}
+		text := fmt.Sprintf("%s %.1f", seg.abbr, seg.pct)
+		if used+len(text)+2 > width {
+			break
+		}
+		used += len(text) + 2
+		parts = append(parts, lipgloss.NewStyle().Foreground(seg.color).Render(text))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// renderStackedBar renders a bar like renderBar with one coloured run
+// per segment. Segment boundaries are rounded from the running total so
+// the runs always add up to the rounded total.
+func renderStackedBar(segs []barSegment, label string, maxWidth int) string {
+	if maxWidth < 1 {
+		maxWidth = 1
+	}
+
+	barWidth := maxWidth - (len(label) + 2) - 1
+	if barWidth < 4 {
+		barWidth = 4
+	}
+
+	var fill strings.Builder
+	sum, drawn := 0.0, 0
+	for _, seg := range segs {
+		sum += seg.pct
+		end := min(int(sum/100*float64(barWidth)+0.5), barWidth)
+		if end > drawn {
+			fill.WriteString(lipgloss.NewStyle().Foreground(seg.color).Render(strings.Repeat("█", end-drawn)))
+			drawn = end
+		}
+	}
+	empty := lipgloss.NewStyle().Foreground(ColorBorder).Render(strings.Repeat("░", barWidth-drawn))
+
+	return fmt.Sprintf("%s [%s%s]", label, fill.String(), empty)
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+func TestCPUGroups_Hybrid(t *testing.T) {
+	cpu := metrics.CPUStats{
+		PerCore: []float64{10, 20, 30, 40},
+		Cores: []metrics.CPUCore{
+			{CPU: 0, Core: 0, Type: metrics.CoreTypeEfficiency, MHz: 800, MaxMHz: 3800},
+			{CPU: 1, Core: 4, Type: metrics.CoreTypePerformance, MHz: 4000, MaxMHz: 5200},
+			{CPU: 2, Core: 4, Type: metrics.CoreTypePerformance, MHz: 4000, MaxMHz: 5400},
+			{CPU: 3, Core: 1, Type: metrics.CoreTypeEfficiency, MHz: 1200, MaxMHz: 3800},
+		},
+	}
+	groups := cpuGroups(cpu)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	if g := groups[0]; g.title != "performance cores · max 5400 MHz" || len(g.cpus) != 2 || g.cpus[0] != 1 {
+		t.Errorf("unexpected first group %+v", g)
+	}
+	if g := groups[1]; g.title != "efficiency cores · max 3800 MHz" || len(g.cpus) != 2 || g.cpus[1] != 3 {
+		t.Errorf("unexpected second group %+v", g)
+	}
+	if got, want := cpuSummary(cpu), "3 cores · 4 threads · 1P + 2E · avg 2500 MHz"; got != want {
+		t.Errorf("cpuSummary = %q, want %q", got, want)
+	}
+	if out := RenderCPU(cpu, 80, nil); !strings.Contains(out, "performance cores") || !strings.Contains(out, "cpu1   20.0% 4000") {
+		t.Errorf("RenderCPU missing group title or clock:\n%s", out)
+	}
+}
+
+func TestCPUGroups_NoTopology(t *testing.T) {
+	cpu := metrics.CPUStats{PerCore: []float64{1, 2, 3}}
+	if groups := cpuGroups(cpu); len(groups) != 1 || groups[0].title != "" || len(groups[0].cpus) != 3 {
+		t.Errorf("expected one untitled group, got %+v", groups)
+	}
+	if got := cpuSummary(cpu); got != "3 cores" {
+		t.Errorf("cpuSummary = %q, want %q", got, "3 cores")
+	}
+}
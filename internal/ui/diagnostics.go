@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// RenderDiagnostics renders the diagnostics overlay: zombies with their
+// parent, processes in D state and reparented processes. cursor indexes
+// the rows in the order of Diagnostics.At; status is the status line of
+// an action started from the overlay.
+func RenderDiagnostics(d metrics.Diagnostics, cursor int, status string, now time.Time, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render("Diagnostics"))
+	b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %d zombie · %d in D state · %d reparented",
+		len(d.Zombies), len(d.Stuck), len(d.Reparented))))
+	b.WriteString("\n\n")
+
+	// Each section gets a share of the rows that fit, and keeps the
+	// cursor row in view.
+	rows := max((height-20)/3, 3)
+	row := 0
+	section := func(title, hint, header string, list []metrics.ProcessProblem, line func(metrics.ProcessProblem) string) {
+		b.WriteString(HeaderStyle.Render(title) + SubtleStyle.Render("  "+hint) + "\n")
+		if len(list) == 0 {
+			b.WriteString(SubtleStyle.Render("  none") + "\n\n")
+			return
+		}
+		b.WriteString(SubtleStyle.Render("  "+header) + "\n")
+		sel := cursor - row
+		if sel < 0 || sel >= len(list) {
+			sel = -1
+		}
+		start, end := visibleRange(len(list), sel, rows)
+		for i := start; i < end; i++ {
+			text := line(list[i])
+			if row+i == cursor {
+				b.WriteString(lipgloss.NewStyle().Background(ColorSelectedBg).Bold(true).Render("▎ "+text) + "\n")
+			} else {
+				b.WriteString("  " + text + "\n")
+			}
+		}
+		if hidden := len(list) - (end - start); hidden > 0 {
+			b.WriteString(SubtleStyle.Render(fmt.Sprintf("  +%d more", hidden)) + "\n")
+		}
+		b.WriteString("\n")
+		row += len(list)
+	}
+
+	section("Zombies", "exited, not yet reaped by their parent",
+		fmt.Sprintf("%7s  %-16s %-24s %s", "PID", "NAME", "PARENT", "FOR"), d.Zombies,
+		func(p metrics.ProcessProblem) string {
+			return fmt.Sprintf("%7d  %-16.16s %-24.24s %s", p.Process.PID, p.Process.Name, procLabel(p.Parent), ageCell(now.Sub(p.Since)))
+		})
+	section("Uninterruptible sleep (D)", "blocked in the kernel, usually on I/O",
+		fmt.Sprintf("%7s  %-16s %-10s %s", "PID", "NAME", "USER", "FOR"), d.Stuck,
+		func(p metrics.ProcessProblem) string {
+			return fmt.Sprintf("%7d  %-16.16s %-10.10s %s", p.Process.PID, p.Process.Name, p.Process.User, ageCell(now.Sub(p.Since)))
+		})
+	section("Reparented", "parent exited",
+		fmt.Sprintf("%7s  %-16s %-24s %-24s %s", "PID", "NAME", "NOW UNDER", "WAS UNDER", "AGO"), d.Reparented,
+		func(p metrics.ProcessProblem) string {
+			return fmt.Sprintf("%7d  %-16.16s %-24.24s %-24.24s %s", p.Process.PID, p.Process.Name,
+				procLabel(p.Parent), procLabel(p.FormerParent), ageCell(now.Sub(p.Since)))
+		})
+
+	if status != "" {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Render("  "+status) + "\n")
+	}
+	b.WriteString(SubtleStyle.Render("  ↑↓ move  │  X signal (a zombie's parent)  │  Enter show in table  │  D or Esc close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Width(width - 4).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// procLabel renders a process as "PID name", or "-" for none.
+func procLabel(p metrics.ProcessInfo) string {
+	if p.PID == 0 {
+		return "-"
+	}
+	if p.Name == "" {
+		return fmt.Sprintf("%d", p.PID)
+	}
+	return fmt.Sprintf("%d %s", p.PID, p.Name)
+}
+
+// ageCell formats a duration compactly: 12s, 4m05s, 2h13m, 3d04h.
+func ageCell(d time.Duration) string {
+	s := int(d.Seconds())
+	switch {
+	case s < 60:
+		return fmt.Sprintf("%ds", max(s, 0))
+	case s < 3600:
+		return fmt.Sprintf("%dm%02ds", s/60, s%60)
+	case s < 86400:
+		return fmt.Sprintf("%dh%02dm", s/3600, s/60%60)
+	}
+	return fmt.Sprintf("%dd%02dh", s/86400, s/3600%24)
+}
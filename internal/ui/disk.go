@@ -7,8 +7,9 @@ import (
 	"github.com/youhide/hideTop/internal/metrics"
 )
 
-// RenderDisk renders the disk panel with I/O throughput and usage.
-func RenderDisk(delta metrics.DiskDelta, disk metrics.DiskStats, width int) string {
+// RenderDisk renders the disk panel with I/O throughput and usage. procs
+// are used to name the process doing the most I/O.
+func RenderDisk(delta metrics.DiskDelta, disk metrics.DiskStats, procs []metrics.ProcessInfo, width int) string {
 	if !disk.Available {
 		return ""
 	}
@@ -24,6 +25,11 @@ func RenderDisk(delta metrics.DiskDelta, disk metrics.DiskStats, width int) stri
 			YellowStyle.Render(formatBytes(delta.WriteSec)),
 		))
 		b.WriteByte('\n')
+		if top, ok := topIOProcess(procs); ok {
+			name := truncateRunes(top.Name, 20)
+			b.WriteString(SubtleStyle.Render(fmt.Sprintf("  top   %s (%d)  %s/s", name, top.PID, formatBytes(top.ReadRate+top.WriteRate))))
+			b.WriteByte('\n')
+		}
 	}
 
 	// Root filesystem usage
@@ -35,3 +41,16 @@ func RenderDisk(delta metrics.DiskDelta, disk metrics.DiskStats, width int) stri
 
 	return PanelStyle.Width(width - 2).Render(b.String())
 }
+
+// topIOProcess returns the process with the highest read+write rate, if
+// any process is doing I/O.
+func topIOProcess(procs []metrics.ProcessInfo) (metrics.ProcessInfo, bool) {
+	var top metrics.ProcessInfo
+	found := false
+	for _, p := range procs {
+		if rate := p.ReadRate + p.WriteRate; rate > 0 && (!found || rate > top.ReadRate+top.WriteRate) {
+			top, found = p, true
+		}
+	}
+	return top, found
+}
@@ -11,11 +11,12 @@ func RenderHelp(width int) string {
 	keys := []struct{ key, desc string }{
 		{"↑↓/jk", "move"},
 		{"/", "search"},
-		{"c/m/p", "sort"},
+		{"c/m/p/</>", "sort"},
 		{"t", "tree"},
+		{"g", "group"},
 		{"s", "sys filter"},
 		{"Enter", "detail"},
-		{"x/K", "kill"},
+		{"x/K/X", "signal"},
 		{"+/-", "interval"},
 		{"e", "export"},
 		{"?", "help"},
@@ -50,26 +51,47 @@ func RenderHelpOverlay(width, height int, version string) string {
 			keys: []struct{ key, desc string }{
 				{"↑ / k", "Move up in process list"},
 				{"↓ / j", "Move down in process list"},
-				{"/", "Start incremental search"},
-				{"Esc", "Cancel search / close help / close detail"},
+				{"/", "Search or filter (user:x cpu>5 state:Z name~re)"},
+				{"F", "Cycle named filters from the config file"},
+				{"Esc", "Cancel search / close help / close detail / leave group"},
 				{"Enter", "Open process detail panel"},
+				{"Tab / 1-6", "Detail panel: switch tab (↑↓ scroll)"},
 			},
 		},
 		{
 			title: "Sorting",
 			keys: []struct{ key, desc string }{
-				{"c", "Sort by CPU% (descending)"},
-				{"m", "Sort by MEM% (descending)"},
-				{"p", "Sort by PID (ascending)"},
+				{"c/m/i", "Sort by CPU% / MEM% / disk I/O (descending)"},
+				{"r/T/o", "Sort by RSS / threads / start time (descending)"},
+				{"p/u/n/S", "Sort by PID / user / name / state (ascending)"},
+				{"p (grouped)", "Rank groups by process count (descending)"},
+				{"(same key)", "Press the active sort key again to reverse"},
+				{"< / >", "Sort by previous / next column"},
 			},
 		},
 		{
 			title: "Process Actions",
 			keys: []struct{ key, desc string }{
 				{"t", "Toggle tree view"},
+				{"← / →", "Collapse / expand subtree (tree view)"},
+				{"g", "Group by container / systemd unit / user / off"},
+				{"C", "Choose and reorder process columns"},
 				{"s", "Toggle system process filter"},
-				{"x", "Kill selected process (SIGTERM)"},
+				{"Space", "Tag / untag process and move down"},
+				{"* / U", "Tag all shown (e.g. search matches) / untag all"},
+				{"x", "Kill selected or tagged processes (SIGTERM)"},
 				{"K", "Force kill (SIGKILL)"},
+				{"X", "Send a signal (HUP, INT, USR1, STOP, CONT…)"},
+				{"N", "Renice selected process"},
+				{"A", "Set CPU affinity of selected process (Linux)"},
+			},
+		},
+		{
+			title: "Replay (--replay)",
+			keys: []struct{ key, desc string }{
+				{"Space", "Pause / resume playback"},
+				{". / ,", "Step one frame forward / back"},
+				{"] / [", "Double / halve playback speed"},
 			},
 		},
 		{
@@ -78,6 +100,10 @@ func RenderHelpOverlay(width, height int, version string) string {
 				{"+/=", "Increase refresh interval (+250ms)"},
 				{"-/_", "Decrease refresh interval (-250ms)"},
 				{"e", "Export snapshot to JSON"},
+				{"a", "Show firing and recent alerts"},
+				{"D", "Diagnostics: zombies, D state, reparented"},
+				{"L", "Process start / exit log"},
+				{"M", "Expand / collapse memory breakdown"},
 				{"?", "Toggle this help overlay"},
 				{"q / Ctrl+C", "Quit"},
 			},
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// RenderProcessEvents renders the process lifecycle overlay. events are
+// the rows in display order; view describes their filter and order for
+// the title line; status is the status line of an action started from
+// the overlay.
+func RenderProcessEvents(events []metrics.ProcessEvent, total int, view string, cursor int, status string, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render("Process events"))
+	b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %s · %d of %d logged", view, len(events), total)))
+	b.WriteString("\n\n")
+
+	b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %-8s  %-5s %7s  %-16s %-10s %9s %8s %8s",
+		"TIME", "EVENT", "PID", "NAME", "USER", "LIFETIME", "MAX CPU%", "MAX MEM%")) + "\n")
+	if len(events) == 0 {
+		b.WriteString(SubtleStyle.Render("  none yet — starts and exits appear from the second process sample on") + "\n")
+	}
+	rows := max(height-14, 3)
+	start, end := visibleRange(len(events), cursor, rows)
+	for i := start; i < end; i++ {
+		ev := events[i]
+		kind := GreenStyle.Render(fmt.Sprintf("%-5s", ev.Kind))
+		lifetime := ""
+		if ev.Kind == metrics.ProcessExited {
+			kind = lipgloss.NewStyle().Foreground(ColorRed).Render(fmt.Sprintf("%-5s", ev.Kind))
+			lifetime = ageCell(ev.Lifetime)
+			if ev.Lifetime < time.Second {
+				lifetime = fmt.Sprintf("%dms", ev.Lifetime.Milliseconds())
+			}
+		}
+		text := fmt.Sprintf("%-8s  %s %7d  %-16.16s %-10.10s %9s %s %s",
+			ev.Time.Format("15:04:05"), kind, ev.PID, ev.Name, ev.User, lifetime,
+			lipgloss.NewStyle().Foreground(BarColor(ev.PeakCPU)).Render(fmt.Sprintf("%8.1f", ev.PeakCPU)),
+			fmt.Sprintf("%8.1f", ev.PeakMem))
+		if i == cursor {
+			b.WriteString(lipgloss.NewStyle().Background(ColorSelectedBg).Bold(true).Render("▎ "+text) + "\n")
+		} else {
+			b.WriteString("  " + text + "\n")
+		}
+	}
+	if hidden := len(events) - (end - start); hidden > 0 {
+		b.WriteString(SubtleStyle.Render(fmt.Sprintf("  +%d more", hidden)) + "\n")
+	}
+	b.WriteString("\n")
+
+	if status != "" {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Render("  "+status) + "\n")
+	}
+	b.WriteString(SubtleStyle.Render("  ↑↓ move  │  f starts / exits / all  │  c order by peak CPU  │  Enter show in table  │  L or Esc close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Width(width - 4).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
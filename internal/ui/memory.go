@@ -1,30 +1,54 @@
 package ui
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/youhide/hideTop/internal/metrics"
 )
 
-func RenderMemory(mem metrics.MemoryStats, load metrics.LoadAvg, width int, history []float64) string {
+// RenderMemory renders the memory panel. Where the system reports a
+// breakdown, the bar is stacked like htop's (used, buffers, shared,
+// cache) with a legend; expanded adds buffers, cache, slab, dirty pages,
+// commit and huge pages below it.
+func RenderMemory(mem metrics.MemoryStats, load metrics.LoadAvg, psi metrics.PressureStats, width int, history []float64, expanded bool) string {
 	var b strings.Builder
 
 	b.WriteString(HeaderStyle.Render("Memory"))
 	b.WriteByte('\n')
 
-	label := fmt.Sprintf("used %5.1f%%  %.1f/%.1f GiB", mem.Percent, mem.UsedGB, mem.TotalGB)
-	b.WriteString(renderBar(mem.Percent, label, width-4))
+	label := fmt.Sprintf("used %5.1f%%  %.1f/%.1f GiB", mem.Percent, gib(mem.Used), gib(mem.Total))
+	if mem.HasBreakdown() {
+		segs := memSegments(mem)
+		b.WriteString(renderStackedBar(segs, label, width-4))
+		b.WriteByte('\n')
+		b.WriteString(memLegend(segs, mem.Total, width-4))
+	} else {
+		b.WriteString(renderBar(mem.Percent, label, width-4))
+	}
 	b.WriteByte('\n')
-	if mem.SwapTotalGB > 0 {
-		swapLabel := fmt.Sprintf("swap %5.1f%%  %.1f/%.1f GiB", mem.SwapPercent, mem.SwapUsedGB, mem.SwapTotalGB)
+	if mem.SwapTotal > 0 {
+		swapLabel := fmt.Sprintf("swap %5.1f%%  %.1f/%.1f GiB", mem.SwapPercent, gib(mem.SwapUsed), gib(mem.SwapTotal))
 		b.WriteString(renderBar(mem.SwapPercent, swapLabel, width-4))
 		b.WriteByte('\n')
 	}
+	if expanded {
+		for _, line := range memDetailLines(mem, width-4) {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
 
 	b.WriteString(SubtleStyle.Render(
 		fmt.Sprintf("  load: %.2f  %.2f  %.2f", load.Load1, load.Load5, load.Load15),
 	))
+	for _, line := range pressureLines(psi, width-4) {
+		b.WriteByte('\n')
+		b.WriteString(line)
+	}
 
 	// Sparkline history
 	if len(history) > 1 {
@@ -34,3 +58,104 @@ func RenderMemory(mem metrics.MemoryStats, load metrics.LoadAvg, width int, hist
 
 	return PanelStyle.Width(width - 2).Render(b.String())
 }
+
+// memSegments splits memory in use the way htop and free do: used by
+// processes and the kernel, buffers, shared memory, and page cache with
+// reclaimable slab. Whatever is left is free.
+func memSegments(mem metrics.MemoryStats) []barSegment {
+	pct := func(v uint64) float64 {
+		if mem.Total == 0 {
+			return 0
+		}
+		return float64(v) / float64(mem.Total) * 100
+	}
+	cache := mem.Cached + mem.SlabReclaimable - min(mem.Shared, mem.Cached+mem.SlabReclaimable)
+	used := mem.Used
+	if reclaimable := mem.Free + mem.Buffers + mem.Cached + mem.SlabReclaimable; reclaimable <= mem.Total {
+		used = mem.Total - reclaimable
+	}
+	return []barSegment{
+		{"used", pct(used), ColorGreen},
+		{"buffers", pct(mem.Buffers), ColorCyan},
+		{"shared", pct(mem.Shared), ColorMagenta},
+		{"cache", pct(cache), ColorYellow},
+	}
+}
+
+// memLegend renders the segments as "cache 5.2G  used 3.1G …" in their
+// colours, largest first and as many as fit in width. Empty segments are
+// left out.
+func memLegend(segs []barSegment, total uint64, width int) string {
+	segs = slices.Clone(segs)
+	slices.SortStableFunc(segs, func(a, b barSegment) int { return cmp.Compare(b.pct, a.pct) })
+	var parts []string
+	used := 0
+	for _, seg := range segs {
+		if seg.pct <= 0 {
+			continue
+		}
+		text := fmt.Sprintf("%s %s", seg.abbr, compactBytes(seg.pct/100*float64(total)))
+		if used+len(text)+2 > width {
+			continue
+		}
+		used += len(text) + 2
+		parts = append(parts, lipgloss.NewStyle().Foreground(seg.color).Render(text))
+	}
+	return "  " + strings.Join(parts, "  ")
+}
+
+// memDetailLines renders the expanded breakdown as label / value pairs,
+// two per line when width allows. Values the system does not report are
+// left out.
+func memDetailLines(mem metrics.MemoryStats, width int) []string {
+	type pair struct{ label, value string }
+	pairs := []pair{
+		{"available", formatBytes(float64(mem.Available))},
+		{"free", formatBytes(float64(mem.Free))},
+	}
+	if mem.HasBreakdown() {
+		pairs = append(pairs,
+			pair{"buffers", formatBytes(float64(mem.Buffers))},
+			pair{"cached", formatBytes(float64(mem.Cached))},
+			pair{"shared", formatBytes(float64(mem.Shared))},
+			pair{"dirty", formatBytes(float64(mem.Dirty))},
+			pair{"slab rec.", formatBytes(float64(mem.SlabReclaimable))},
+			pair{"slab unrec.", formatBytes(float64(mem.SlabUnreclaimable))},
+			pair{"writeback", formatBytes(float64(mem.Writeback))},
+		)
+	}
+
+	const cellW = 23 // "  " + 11 label + 10 value
+	cell := func(p pair) string {
+		return SubtleStyle.Render(fmt.Sprintf("  %-11s", p.label)) + fmt.Sprintf("%10s", p.value)
+	}
+	perLine := 1
+	if width >= 2*cellW {
+		perLine = 2
+	}
+	var lines []string
+	for i := 0; i < len(pairs); i += perLine {
+		line := cell(pairs[i])
+		if perLine == 2 && i+1 < len(pairs) {
+			line += cell(pairs[i+1])
+		}
+		lines = append(lines, line)
+	}
+
+	if mem.CommitLimit > 0 {
+		pct := float64(mem.CommittedAS) / float64(mem.CommitLimit) * 100
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("  %-11s", "committed"))+
+			lipgloss.NewStyle().Foreground(BarColor(pct)).Render(fmt.Sprintf("%s / %s (%.0f%%)",
+				compactBytes(float64(mem.CommittedAS)), compactBytes(float64(mem.CommitLimit)), pct)))
+	}
+	if mem.HugePagesTotal > 0 {
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("  %-11s", "hugepages"))+
+			fmt.Sprintf("%d/%d used × %s", mem.HugePagesTotal-mem.HugePagesFree, mem.HugePagesTotal,
+				compactBytes(float64(mem.HugePageSize))))
+	}
+	return lines
+}
+
+func gib(b uint64) float64 {
+	return float64(b) / (1 << 30)
+}
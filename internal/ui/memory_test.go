@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+func TestMemSegments(t *testing.T) {
+	mem := metrics.MemoryStats{
+		Total: 1000, Used: 300, Free: 200,
+		Buffers: 50, Cached: 400, Shared: 100, SlabReclaimable: 50,
+	}
+	segs := memSegments(mem)
+	// used = total - free - buffers - cached - reclaimable slab,
+	// cache = cached + reclaimable slab - shared.
+	want := map[string]float64{"used": 30, "buffers": 5, "shared": 10, "cache": 35}
+	sum := 0.0
+	for _, s := range segs {
+		if s.pct != want[s.abbr] {
+			t.Errorf("%s = %.1f%%, want %.1f%%", s.abbr, s.pct, want[s.abbr])
+		}
+		sum += s.pct
+	}
+	if sum != 80 {
+		t.Errorf("segments add up to %.1f%%, want everything but free (80%%)", sum)
+	}
+
+	if got := memLegend(segs, mem.Total, 80); !strings.HasPrefix(got, "  cache 350B  used 300B") {
+		t.Errorf("expected the legend largest first, got %q", got)
+	}
+}
+
+func TestRenderMemory_Expanded(t *testing.T) {
+	mem := metrics.MemoryStats{
+		Total: 8 << 30, Used: 2 << 30, Available: 6 << 30, Free: 1 << 30, Percent: 25,
+		Buffers: 1 << 28, Cached: 4 << 30, CommittedAS: 3 << 30, CommitLimit: 6 << 30,
+	}
+	collapsed := RenderMemory(mem, metrics.LoadAvg{}, metrics.PressureStats{}, 60, nil, false)
+	if strings.Contains(collapsed, "committed") {
+		t.Errorf("expected no breakdown when collapsed:\n%s", collapsed)
+	}
+	expanded := RenderMemory(mem, metrics.LoadAvg{}, metrics.PressureStats{}, 60, nil, true)
+	for _, want := range []string{"buffers", "slab rec.", "3.0G / 6.0G (50%)"} {
+		if !strings.Contains(expanded, want) {
+			t.Errorf("expanded panel missing %q:\n%s", want, expanded)
+		}
+	}
+	if strings.Contains(expanded, "hugepages") {
+		t.Errorf("expected huge pages to be left out when none are reserved:\n%s", expanded)
+	}
+}
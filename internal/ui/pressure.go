@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// PressureColor returns a color based on PSI stall percentage
+// thresholds: sustained stalls above a few percent are noticeable,
+// above a quarter of the time they hurt.
+func PressureColor(pct float64) lipgloss.Color {
+	switch {
+	case pct >= 25:
+		return ColorRed
+	case pct >= 5:
+		return ColorYellow
+	default:
+		return ColorGreen
+	}
+}
+
+// pressureLines renders PSI as a header and one row per resource: the
+// "some" averages over 10s, 60s and 300s, then the 10s "full" average.
+// The full column is dropped when width is too narrow for it. Returns
+// nil when PSI is not available.
+func pressureLines(p metrics.PressureStats, width int) []string {
+	if !p.Available {
+		return nil
+	}
+	full := width >= 38
+	header := fmt.Sprintf("  %-8s%6s%6s%6s", "psi some", "10s", "60s", "300s")
+	if full {
+		header += fmt.Sprintf("    %6s", "full")
+	}
+	lines := []string{SubtleStyle.Render(header)}
+	for _, r := range []struct {
+		name string
+		p    metrics.Pressure
+	}{
+		{"cpu", p.CPU}, {"memory", p.Memory}, {"io", p.IO},
+	} {
+		row := SubtleStyle.Render(fmt.Sprintf("  %-8s", r.name))
+		for _, v := range []float64{r.p.Some.Avg10, r.p.Some.Avg60, r.p.Some.Avg300} {
+			row += pressureCell(v)
+		}
+		if full {
+			row += "    " + pressureCell(r.p.Full.Avg10)
+		}
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+func pressureCell(pct float64) string {
+	return lipgloss.NewStyle().Foreground(PressureColor(pct)).Render(fmt.Sprintf("%6.1f", pct))
+}
@@ -3,19 +3,46 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/youhide/hideTop/internal/metrics"
 )
 
-// ProcessDetail holds extended info for the process detail overlay.
+// DetailTabs are the tabs of the process detail overlay, in order.
+var DetailTabs = []string{"Overview", "Env", "Files", "Memory", "Threads", "Cgroup"}
+
+// ProcessDetail holds the state of the process detail overlay. The
+// embedded ProcessInfo is refreshed from every snapshot while it is open.
 type ProcessDetail struct {
 	metrics.ProcessInfo
-	Cmdline    string
-	NumFDs     int32
-	RSS        uint64 // bytes
-	VMS        uint64 // bytes
-	CreateTime int64  // milliseconds since epoch
+	Inspection metrics.ProcessInspection
+	CPUHistory []float64 // CPU% per refresh
+	RSSHistory []float64 // RSS bytes per refresh
+	Tab        int
+	Scroll     int  // first body line shown
+	Local      bool // the process runs on this host and can be inspected
+	Exited     bool // the process is gone; the last values are kept
+}
+
+// detailOverhead is the overlay's height outside the scrolling body:
+// border, padding, title, tab bar and footer.
+const detailOverhead = 10
+
+// DetailMaxScroll returns the largest useful Scroll for d's current tab.
+func DetailMaxScroll(d ProcessDetail, width, height int) int {
+	n := len(detailBody(d, width)) - detailBodyRows(height)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func detailBodyRows(height int) int {
+	if rows := height - detailOverhead; rows > 3 {
+		return rows
+	}
+	return 3
 }
 
 // RenderProcessDetail renders a full-screen overlay with extended process info.
@@ -24,19 +51,98 @@ func RenderProcessDetail(d ProcessDetail, width, height int) string {
 
 	title := fmt.Sprintf("Process %d — %s", d.PID, d.Name)
 	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render(title))
+	if d.Exited {
+		b.WriteString("  " + lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Render("exited"))
+	}
+	b.WriteString("\n\n")
+
+	for i, name := range DetailTabs {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if i == d.Tab {
+			b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(ColorSelectedBg).Render(label))
+		} else {
+			b.WriteString(SubtleStyle.Render(label))
+		}
+	}
 	b.WriteString("\n\n")
 
-	field := func(label, value string) {
-		b.WriteString(fmt.Sprintf("  %s  %s\n",
-			lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Width(14).Render(label),
-			SubtleStyle.Render(value),
-		))
+	body := detailBody(d, width)
+	rows := detailBodyRows(height)
+	start := min(max(d.Scroll, 0), max(len(body)-rows, 0))
+	end := min(start+rows, len(body))
+	for _, line := range body[start:end] {
+		b.WriteString(line + "\n")
+	}
+	if start > 0 || end < len(body) {
+		b.WriteString(SubtleStyle.Render(fmt.Sprintf("  lines %d-%d of %d", start+1, end, len(body))) + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SubtleStyle.Render("  Tab/←→ switch tab  │  ↑↓ scroll  │  Esc close"))
+
+	content := b.String()
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Width(width - 4).
+		Render(content)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// detailBody returns the lines of d's current tab.
+func detailBody(d ProcessDetail, width int) []string {
+	inner := width - 12 // border, padding and indent
+	if inner < 20 {
+		inner = 20
+	}
+	if d.Tab > 0 && !d.Local {
+		return []string{SubtleStyle.Render("  Only available for processes on this host.")}
+	}
+	switch d.Tab {
+	case 1:
+		return detailEnv(d.Inspection, inner)
+	case 2:
+		return detailFiles(d.Inspection, inner)
+	case 3:
+		return detailMemory(d, inner)
+	case 4:
+		return detailThreads(d.Inspection, inner)
+	case 5:
+		return detailCgroup(d, inner)
+	default:
+		return detailOverview(d, inner)
+	}
+}
+
+func detailField(label, value string) string {
+	return fmt.Sprintf("  %s  %s",
+		lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Width(14).Render(label),
+		SubtleStyle.Render(value),
+	)
+}
+
+func detailHeading(s string) string {
+	return lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Render("  " + s)
+}
+
+// detailError returns the read error of an inspection section, if any.
+func detailError(in metrics.ProcessInspection, section string) []string {
+	if err, ok := in.Errors[section]; ok {
+		return []string{SubtleStyle.Render("  unavailable: " + err)}
 	}
+	return nil
+}
+
+func detailOverview(d ProcessDetail, inner int) []string {
+	var lines []string
+	field := func(label, value string) { lines = append(lines, detailField(label, value)) }
 
 	field("PID", fmt.Sprintf("%d", d.PID))
 	field("PPID", fmt.Sprintf("%d", d.PPID))
 	field("User", d.User)
-	field("State", stateLabel(d.State)+" ("+d.State+")")
+	field("State", metrics.StateLetter(d.State)+" ("+d.State+")")
 	field("Threads", fmt.Sprintf("%d", d.NumThreads))
 	field("CPU%", fmt.Sprintf("%.1f%%", d.CPUPercent))
 	field("MEM%", fmt.Sprintf("%.1f%%", d.MemPercent))
@@ -50,36 +156,192 @@ func RenderProcessDetail(d ProcessDetail, width, height int) string {
 	if d.NumFDs > 0 {
 		field("Open FDs", fmt.Sprintf("%d", d.NumFDs))
 	}
+	if d.CreateTime > 0 {
+		field("Started", time.UnixMilli(d.CreateTime).Format("2006-01-02 15:04:05"))
+	}
+	if d.CPUTime > 0 {
+		field("CPU time", cpuTimeCell(d.CPUTime))
+	}
+	field("Nice / Prio", fmt.Sprintf("%d / %d", d.Nice, d.Priority))
+	if d.ReadRate > 0 || d.WriteRate > 0 {
+		field("Disk I/O", fmt.Sprintf("read %s/s  write %s/s", formatBytes(d.ReadRate), formatBytes(d.WriteRate)))
+	}
+	if d.Inspection.Exe != "" {
+		field("Executable", d.Inspection.Exe)
+	}
+	if d.Inspection.Cwd != "" {
+		field("Working dir", d.Inspection.Cwd)
+	}
+
+	if len(d.CPUHistory) > 0 {
+		spark := inner - 18
+		field("CPU history", RenderSparkline(scaleHistory(d.CPUHistory, 100), spark, ColorGreen))
+		field("RSS history", RenderSparkline(scaleHistory(d.RSSHistory, 0), spark, ColorYellow))
+	}
 
-	b.WriteString("\n")
 	if d.Cmdline != "" {
-		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Render("  Command Line"))
-		b.WriteString("\n")
+		lines = append(lines, "", detailHeading("Command Line"))
 		// Wrap long command lines
-		cmd := d.Cmdline
-		maxW := width - 12
-		if maxW < 40 {
-			maxW = 40
+		for _, part := range wrapRunes(singleLine(d.Cmdline), inner) {
+			lines = append(lines, "  "+SubtleStyle.Render(part))
 		}
-		for len(cmd) > maxW {
-			b.WriteString("  " + SubtleStyle.Render(cmd[:maxW]) + "\n")
-			cmd = cmd[maxW:]
+	}
+	return lines
+}
+
+func detailEnv(in metrics.ProcessInspection, inner int) []string {
+	if lines := detailError(in, "env"); lines != nil {
+		return lines
+	}
+	if len(in.Environ) == 0 {
+		return []string{SubtleStyle.Render("  no environment variables")}
+	}
+	lines := make([]string, 0, len(in.Environ))
+	for _, kv := range in.Environ {
+		name, value, _ := strings.Cut(kv, "=")
+		lines = append(lines, "  "+lipgloss.NewStyle().Foreground(ColorHeader).Render(name)+"="+
+			SubtleStyle.Render(truncateRunes(singleLine(value), inner-len([]rune(name))-1)))
+	}
+	return lines
+}
+
+func detailFiles(in metrics.ProcessInspection, inner int) []string {
+	if lines := detailError(in, "files"); lines != nil {
+		return lines
+	}
+	var files, sockets []string
+	for _, f := range in.Files {
+		fd := lipgloss.NewStyle().Foreground(ColorHeader).Width(6).Align(lipgloss.Right).Render(fmt.Sprintf("%d", f.FD))
+		switch {
+		case f.Socket != "":
+			sockets = append(sockets, "  "+fd+"  "+truncateRunes(f.Socket, inner-8))
+		case strings.HasPrefix(f.Path, "socket:"):
+			sockets = append(sockets, "  "+fd+"  "+SubtleStyle.Render(f.Path))
+		default:
+			files = append(files, "  "+fd+"  "+SubtleStyle.Render(truncateRunes(f.Path, inner-8)))
 		}
-		if cmd != "" {
-			b.WriteString("  " + SubtleStyle.Render(cmd) + "\n")
+	}
+	lines := []string{detailHeading(fmt.Sprintf("Files (%d)", len(files)))}
+	lines = append(lines, files...)
+	lines = append(lines, "", detailHeading(fmt.Sprintf("Sockets (%d)", len(sockets))))
+	return append(lines, sockets...)
+}
+
+func detailMemory(d ProcessDetail, inner int) []string {
+	in := d.Inspection
+	if lines := detailError(in, "maps"); lines != nil {
+		return lines
+	}
+	var rss, size, swap uint64
+	for _, m := range in.Maps {
+		rss += m.RSS
+		size += m.Size
+		swap += m.Swap
+	}
+	lines := []string{
+		detailField("Mapped", fmt.Sprintf("%s in %d regions", formatBytes(float64(size)), len(in.Maps))),
+		detailField("Resident", formatBytes(float64(rss))),
+		detailField("Swapped", formatBytes(float64(swap))),
+		"",
+		detailHeading(fmt.Sprintf("%9s %9s %5s  %s", "RSS", "SIZE", "MAPS", "PATH")),
+	}
+	for _, m := range in.Maps {
+		lines = append(lines, fmt.Sprintf("  %9s %9s %5d  %s",
+			compactBytes(float64(m.RSS)), compactBytes(float64(m.Size)), m.Count,
+			SubtleStyle.Render(truncateRunes(m.Path, inner-28))))
+	}
+	return lines
+}
+
+func detailThreads(in metrics.ProcessInspection, inner int) []string {
+	if lines := detailError(in, "threads"); lines != nil {
+		return lines
+	}
+	lines := []string{detailHeading(fmt.Sprintf("%7s %7s %9s  %s", "TID", "CPU%", "TIME", "NAME"))}
+	for _, t := range in.Threads {
+		lines = append(lines, fmt.Sprintf("  %7d %s %9s  %s",
+			t.TID,
+			lipgloss.NewStyle().Foreground(BarColor(t.CPUPercent)).Width(7).Align(lipgloss.Right).Render(fmt.Sprintf("%.1f", t.CPUPercent)),
+			cpuTimeCell(t.CPUTime),
+			SubtleStyle.Render(truncateRunes(t.Name, inner-28))))
+	}
+	return lines
+}
+
+func detailCgroup(d ProcessDetail, inner int) []string {
+	var lines []string
+	if d.Container != "" {
+		lines = append(lines, detailField("Container", d.Container))
+	}
+	if d.Unit != "" {
+		lines = append(lines, detailField("Unit", d.Unit))
+	}
+	in := d.Inspection
+	if len(in.Cgroups) == 0 {
+		return append(lines, SubtleStyle.Render("  no cgroup information"))
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, detailHeading("Membership"))
+	for _, cg := range in.Cgroups {
+		lines = append(lines, "  "+SubtleStyle.Render(truncateRunes(cg, inner)))
+	}
+	if len(in.Limits) > 0 {
+		lines = append(lines, "", detailHeading("Limits and usage"))
+		for _, f := range in.Limits {
+			lines = append(lines, detailField(f.Name, cgroupValue(f)))
 		}
 	}
+	if psi := pressureLines(in.Pressure, inner); psi != nil {
+		lines = append(lines, "", detailHeading("Pressure"))
+		lines = append(lines, psi...)
+	}
+	return lines
+}
 
-	b.WriteString("\n")
-	b.WriteString(SubtleStyle.Render("  Press Esc or Enter to close"))
+// cgroupValue formats byte-valued cgroup files for reading.
+func cgroupValue(f metrics.CgroupFile) string {
+	if !strings.HasPrefix(f.Name, "memory.") || f.Value == "max" {
+		return f.Value
+	}
+	var n float64
+	if _, err := fmt.Sscan(f.Value, &n); err != nil {
+		return f.Value
+	}
+	return formatBytes(n)
+}
 
-	content := b.String()
-	box := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ColorTitle).
-		Padding(1, 2).
-		Width(width - 4).
-		Render(content)
+// scaleHistory maps values onto the 0-100 sparkline range. With
+// ceiling > 0 values are clamped to it; otherwise the largest value is
+// the top.
+func scaleHistory(values []float64, ceiling float64) []float64 {
+	top := ceiling
+	if top <= 0 {
+		for _, v := range values {
+			top = max(top, v)
+		}
+	}
+	if top <= 0 {
+		return values
+	}
+	scaled := make([]float64, len(values))
+	for i, v := range values {
+		scaled[i] = min(v, top) / top * 100
+	}
+	return scaled
+}
 
-	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+// wrapRunes splits s into lines of at most width runes.
+func wrapRunes(s string, width int) []string {
+	r := []rune(s)
+	var lines []string
+	for len(r) > width {
+		lines = append(lines, string(r[:width]))
+		r = r[width:]
+	}
+	if len(r) > 0 {
+		lines = append(lines, string(r))
+	}
+	return lines
 }
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/youhide/hideTop/internal/metrics"
+)
+
+// RenderProcessGroups renders the process panel in grouping mode: one row
+// per container, systemd unit or user with summed CPU%, MEM% and RSS.
+func RenderProcessGroups(groups []metrics.ProcessGroup, state ProcessViewState, width, maxRows int) string {
+	var b strings.Builder
+
+	b.WriteString(HeaderStyle.Render("Processes"))
+	label := state.GroupBy.String()
+	if len(groups) != 1 {
+		label += "s"
+	}
+	b.WriteString(SubtleStyle.Render(fmt.Sprintf("  %d %s", len(groups), label)))
+	if state.HideSystem {
+		b.WriteString(SubtleStyle.Render("  [user]"))
+	}
+	if state.SearchQuery != "" || state.Searching {
+		cursor := ""
+		if state.Searching {
+			cursor = "█"
+		}
+		b.WriteString(SubtleStyle.Render("  /" + state.SearchQuery + cursor))
+	}
+	b.WriteByte('\n')
+
+	keyW := width - 4 - 2 - (1 + 6) - (1 + 5) - (1 + 8) - (1 + 8) - (1 + 8) - (1 + 9)
+	if keyW < 12 {
+		keyW = 12
+	}
+	// Fields without a per-group total sort groups by key. PID ranks
+	// them by process count, largest first unless reversed.
+	keyTarget, keyState := noSort, state
+	countState := state
+	countState.SortReverse = !state.SortReverse
+	switch state.SortBy {
+	case metrics.SortByCPU, metrics.SortByMem, metrics.SortByIO, metrics.SortByThreads, metrics.SortByRSS, metrics.SortByPID:
+	default:
+		keyTarget, keyState.SortBy = metrics.SortByName, metrics.SortByName
+	}
+	hdr := "  " +
+		columnHeader(strings.ToUpper(state.GroupBy.String()), keyW, lipgloss.Left, keyState, keyTarget) + " " +
+		columnHeader("PROCS", 6, lipgloss.Right, countState, metrics.SortByPID) + " " +
+		columnHeader("THR", 5, lipgloss.Right, state, metrics.SortByThreads) + " " +
+		columnHeader("CPU%", 8, lipgloss.Right, state, metrics.SortByCPU) + " " +
+		columnHeader("MEM%", 8, lipgloss.Right, state, metrics.SortByMem) + " " +
+		columnHeader("RSS", 8, lipgloss.Right, state, metrics.SortByRSS) + " " +
+		columnHeader("IO/s", 9, lipgloss.Right, state, metrics.SortByIO)
+	b.WriteString(hdr)
+	b.WriteByte('\n')
+
+	sepWidth := width - 4
+	if sepWidth < 1 {
+		sepWidth = 1
+	}
+	b.WriteString(SubtleStyle.Render(strings.Repeat("─", sepWidth)))
+	b.WriteByte('\n')
+
+	n := len(groups)
+	start := 0
+	if maxRows > 0 && state.SelectedIdx >= maxRows {
+		start = state.SelectedIdx - maxRows + 1
+	}
+	end := n
+	if maxRows > 0 && start+maxRows < n {
+		end = start + maxRows
+	}
+
+	innerW := width - 4
+	for i := start; i < end; i++ {
+		g := groups[i]
+		keyStyle := lipgloss.NewStyle().Width(keyW)
+		if g.Key == metrics.GroupKeyHost || g.Key == metrics.GroupKeyNone || g.Key == metrics.GroupKeyUnknown {
+			keyStyle = keyStyle.Foreground(ColorSubtle)
+		}
+		line := fmt.Sprintf("  %s %s %s %s %s %s %s",
+			keyStyle.Render(truncateRunes(g.Key, keyW)),
+			lipgloss.NewStyle().Width(6).Align(lipgloss.Right).Render(fmt.Sprintf("%d", g.Count)),
+			lipgloss.NewStyle().Foreground(ColorSubtle).Width(5).Align(lipgloss.Right).Render(fmt.Sprintf("%d", g.Threads)),
+			lipgloss.NewStyle().Foreground(BarColor(g.CPUPercent)).Width(8).Align(lipgloss.Right).Render(fmt.Sprintf("%.1f", g.CPUPercent)),
+			lipgloss.NewStyle().Foreground(BarColor(g.MemPercent)).Width(8).Align(lipgloss.Right).Render(fmt.Sprintf("%.1f", g.MemPercent)),
+			renderCell(compactBytes(float64(g.RSS)), 8, lipgloss.Right, ""),
+			renderCell(rateCell(g.ReadRate+g.WriteRate), 9, lipgloss.Right, ColorYellow),
+		)
+
+		if i == state.SelectedIdx {
+			visible := lipgloss.Width(line)
+			if visible < innerW {
+				line += strings.Repeat(" ", innerW-visible)
+			}
+			line = strings.TrimPrefix(line, " ")
+			line = lipgloss.NewStyle().
+				Background(ColorSelectedBg).
+				Bold(true).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Render("▎" + line)
+		}
+
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return PanelStyle.Width(width - 2).Render(b.String())
+}
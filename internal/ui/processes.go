@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/youhide/hideTop/internal/metrics"
@@ -11,25 +13,40 @@ import (
 // ProcessViewState holds pure rendering state for the process panel.
 type ProcessViewState struct {
 	SortBy      metrics.SortField
-	SelectedIdx int // -1 = no selection
+	SortReverse bool // SortBy in the reverse of its default direction
+	SelectedIdx int  // -1 = no selection
 	SearchQuery string
+	SearchError string // parse error of SearchQuery as a filter
 	Searching   bool
 	TreeView    bool
+	Collapsed   map[int32]bool // tree view: PIDs whose descendants are folded into their row
+	Tagged      map[int32]bool // PIDs tagged for batch actions
 	HideSystem  bool
 	TotalProcs  int // total process count before filtering
+	GroupBy     metrics.GroupBy
+	GroupFilter string   // non-empty = showing only this group's processes
+	Columns     []string // column IDs; empty = DefaultColumns
+
+	// Now is when the processes were sampled, which start times are
+	// shown relative to.
+	Now time.Time
 }
 
-func columnHeader(label string, width int, align lipgloss.Position, sortBy, target metrics.SortField) string {
+// columnHeader renders a column title, underlined with a ▲/▼ direction
+// indicator when it is the active sort column.
+func columnHeader(label string, width int, align lipgloss.Position, state ProcessViewState, target metrics.SortField) string {
+	sortBy := state.SortBy
 	indicator := ""
 	if sortBy == target {
-		switch target {
-		case metrics.SortByPID:
-			indicator = " ▲"
-		default:
+		indicator = " ▲"
+		if target.Descending(state.SortReverse) {
 			indicator = " ▼"
 		}
 	}
 	text := label + indicator
+	if lipgloss.Width(text) > width {
+		text = label + strings.TrimSpace(indicator) // e.g. "S▲" in a 2-cell column
+	}
 	style := lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Width(width).Align(align)
 	if sortBy == target {
 		style = style.Underline(true)
@@ -54,27 +71,33 @@ func RenderProcesses(procs []metrics.ProcessInfo, state ProcessViewState, width,
 	if state.TreeView {
 		b.WriteString(SubtleStyle.Render("  [tree]"))
 	}
+	if n := len(state.Tagged); n > 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorYellow).Render(fmt.Sprintf("  [%d tagged]", n)))
+	}
 	if state.HideSystem {
 		b.WriteString(SubtleStyle.Render("  [user]"))
 	}
+	if state.GroupFilter != "" {
+		b.WriteString(SubtleStyle.Render("  [" + state.GroupFilter + "]"))
+	}
 	if state.SearchQuery != "" || state.Searching {
 		cursor := ""
 		if state.Searching {
 			cursor = "█"
 		}
 		b.WriteString(SubtleStyle.Render("  /" + state.SearchQuery + cursor))
+		if state.SearchError != "" {
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorRed).Render("  ✗ " + state.SearchError))
+		}
 	}
 	b.WriteByte('\n')
 
 	// Column headers with sort direction + underline on active column
-	hdr := "  " +
-		columnHeader("PID", 7, lipgloss.Left, state.SortBy, metrics.SortByPID) + " " +
-		columnHeader("S", 2, lipgloss.Left, state.SortBy, metrics.SortField(-1)) + " " +
-		columnHeader("USER", 10, lipgloss.Left, state.SortBy, metrics.SortField(-1)) + " " +
-		columnHeader("NAME", 20, lipgloss.Left, state.SortBy, metrics.SortField(-1)) + " " +
-		columnHeader("THR", 4, lipgloss.Right, state.SortBy, metrics.SortField(-1)) + " " +
-		columnHeader("CPU%", 8, lipgloss.Right, state.SortBy, metrics.SortByCPU) + " " +
-		columnHeader("MEM%", 8, lipgloss.Right, state.SortBy, metrics.SortByMem)
+	cols := layoutColumns(state.Columns, width-4)
+	hdr := " "
+	for _, c := range cols {
+		hdr += " " + columnHeader(c.Title, c.w, c.Align, state, c.Sort)
+	}
 	b.WriteString(hdr)
 	b.WriteByte('\n')
 
@@ -86,75 +109,46 @@ func RenderProcesses(procs []metrics.ProcessInfo, state ProcessViewState, width,
 	b.WriteString(sep)
 	b.WriteByte('\n')
 
-	// Build display list: flat or tree
-	var displayList []displayProc
-
-	if state.TreeView && len(procs) > 0 {
-		displayList = buildTreeDisplay(procs)
-	} else {
-		for _, p := range procs {
-			displayList = append(displayList, displayProc{proc: p})
-		}
-	}
-
-	// Compute visible window that keeps selection on screen
-	n := len(displayList)
-	start := 0
-	if maxRows > 0 && state.SelectedIdx >= maxRows {
-		start = state.SelectedIdx - maxRows + 1
-	}
-	end := n
-	if maxRows > 0 {
-		end = start + maxRows
-	}
-	if end > n {
-		end = n
-		if maxRows > 0 {
-			start = end - maxRows
-			if start < 0 {
-				start = 0
-			}
-		}
-	}
+	displayList := displayRows(procs, state)
+	start, end := visibleRange(len(displayList), state.SelectedIdx, maxRows)
 
 	innerW := width - 4
 	for i := start; i < end; i++ {
 		dp := displayList[i]
-		p := dp.proc
-		user := truncateRunes(p.User, 10)
-		name := dp.prefix + truncateRunes(p.Name, 20-len(dp.prefix))
-
-		cpuColor := BarColor(p.CPUPercent)
-		memColor := BarColor(float64(p.MemPercent))
-
-		stateChar := stateLabel(p.State)
-
-		thrStr := ""
-		if p.NumThreads > 0 {
-			thrStr = fmt.Sprintf("%d", p.NumThreads)
+		dp.now = state.Now
+		tagged := state.Tagged[dp.proc.PID]
+		line := ""
+		for j, c := range cols {
+			text, color := c.value(dp)
+			if lead := dp.prefix + dp.marker; c.ID == "name" && lead != "" {
+				text = lead + truncateRunes(text, c.w-utf8.RuneCountInString(lead))
+			}
+			if tagged {
+				color = ColorYellow
+			}
+			if j > 0 {
+				line += " "
+			}
+			line += renderCell(text, c.w, c.Align, color)
+		}
+		mark := " "
+		if tagged {
+			mark = "●"
 		}
-
-		line := fmt.Sprintf("  %-7d %s %-10s %-20s %s %s %s",
-			p.PID,
-			lipgloss.NewStyle().Foreground(stateColor(p.State)).Width(2).Render(stateChar),
-			user,
-			name,
-			lipgloss.NewStyle().Foreground(ColorSubtle).Width(4).Align(lipgloss.Right).Render(thrStr),
-			lipgloss.NewStyle().Foreground(cpuColor).Width(8).Align(lipgloss.Right).Render(fmt.Sprintf("%.1f", p.CPUPercent)),
-			lipgloss.NewStyle().Foreground(memColor).Width(8).Align(lipgloss.Right).Render(fmt.Sprintf("%.1f", p.MemPercent)),
-		)
 
 		if state.SelectedIdx >= 0 && i == state.SelectedIdx {
+			line = "▎" + mark + line
 			visible := lipgloss.Width(line)
 			if visible < innerW {
 				line += strings.Repeat(" ", innerW-visible)
 			}
-			line = strings.TrimPrefix(line, " ")
 			line = lipgloss.NewStyle().
 				Background(ColorSelectedBg).
 				Bold(true).
 				Foreground(lipgloss.Color("#FFFFFF")).
-				Render("▎" + line)
+				Render(line)
+		} else {
+			line = " " + lipgloss.NewStyle().Foreground(ColorYellow).Render(mark) + line
 		}
 
 		b.WriteString(line)
@@ -164,14 +158,82 @@ func RenderProcesses(procs []metrics.ProcessInfo, state ProcessViewState, width,
 	return PanelStyle.Width(width - 2).Render(b.String())
 }
 
-// displayProc wraps a process with a tree-indent prefix.
+// VisibleProcesses returns the processes RenderProcesses shows for the
+// same arguments, in display order.
+func VisibleProcesses(procs []metrics.ProcessInfo, state ProcessViewState, maxRows int) []metrics.ProcessInfo {
+	rows := displayRows(procs, state)
+	start, end := visibleRange(len(rows), state.SelectedIdx, maxRows)
+	visible := make([]metrics.ProcessInfo, 0, end-start)
+	for _, dp := range rows[start:end] {
+		visible = append(visible, dp.proc)
+	}
+	return visible
+}
+
+// displayRows returns the display list: flat, or tree-ordered in tree view.
+func displayRows(procs []metrics.ProcessInfo, state ProcessViewState) []displayProc {
+	if state.TreeView && len(procs) > 0 {
+		return buildTree(procs, state.Collapsed)
+	}
+	rows := make([]displayProc, 0, len(procs))
+	for _, p := range procs {
+		rows = append(rows, displayProc{proc: p})
+	}
+	return rows
+}
+
+// visibleRange returns the window [start, end) of n rows that keeps the
+// selected row on screen. maxRows <= 0 shows every row.
+func visibleRange(n, selected, maxRows int) (start, end int) {
+	if maxRows > 0 && selected >= maxRows {
+		start = selected - maxRows + 1
+	}
+	end = n
+	if maxRows > 0 {
+		end = start + maxRows
+	}
+	if end > n {
+		end = n
+		if maxRows > 0 {
+			start = end - maxRows
+			if start < 0 {
+				start = 0
+			}
+		}
+	}
+	return start, end
+}
+
+// TreeOrder returns procs in the order tree view shows them, leaving out
+// the descendants of collapsed processes.
+func TreeOrder(procs []metrics.ProcessInfo, collapsed map[int32]bool) []metrics.ProcessInfo {
+	rows := buildTree(procs, collapsed)
+	ordered := make([]metrics.ProcessInfo, len(rows))
+	for i, dp := range rows {
+		ordered[i] = dp.proc
+	}
+	return ordered
+}
+
+// displayProc wraps a process with a tree-indent prefix and, for
+// processes with children, a collapse marker.
 type displayProc struct {
 	proc   metrics.ProcessInfo
 	prefix string
+	marker string    // "▾ " expanded, "▸ " collapsed, "" no children
+	now    time.Time // ProcessViewState.Now, for relative cells
 }
 
 // buildTreeDisplay builds a tree-ordered display list from a flat process list.
 func buildTreeDisplay(procs []metrics.ProcessInfo) []displayProc {
+	return buildTree(procs, nil)
+}
+
+// buildTree builds a tree-ordered display list. Children keep their order
+// in procs, so each parent's children are sorted by the active sort
+// field. The descendants of collapsed PIDs are left out and their
+// CPU%, MEM%, threads, memory and I/O are summed into the parent's row.
+func buildTree(procs []metrics.ProcessInfo, collapsed map[int32]bool) []displayProc {
 	// Build parent → children map
 	pidSet := make(map[int32]bool)
 	children := make(map[int32][]metrics.ProcessInfo)
@@ -190,8 +252,18 @@ func buildTreeDisplay(procs []metrics.ProcessInfo) []displayProc {
 	var result []displayProc
 	var walk func(p metrics.ProcessInfo, indent string)
 	walk = func(p metrics.ProcessInfo, indent string) {
-		result = append(result, displayProc{proc: p, prefix: indent})
 		kids := children[p.PID]
+		dp := displayProc{proc: p, prefix: indent}
+		if len(kids) > 0 {
+			dp.marker = "▾ "
+			if collapsed[p.PID] {
+				dp.marker = "▸ "
+				addDescendants(&dp.proc, children)
+				result = append(result, dp)
+				return
+			}
+		}
+		result = append(result, dp)
 		for i, child := range kids {
 			childIndent := "├─"
 			if i == len(kids)-1 {
@@ -206,6 +278,24 @@ func buildTreeDisplay(procs []metrics.ProcessInfo) []displayProc {
 	return result
 }
 
+// addDescendants adds the totals of every descendant of p to p.
+func addDescendants(p *metrics.ProcessInfo, children map[int32][]metrics.ProcessInfo) {
+	var add func(pid int32)
+	add = func(pid int32) {
+		for _, c := range children[pid] {
+			p.CPUPercent += c.CPUPercent
+			p.MemPercent += c.MemPercent
+			p.NumThreads += c.NumThreads
+			p.RSS += c.RSS
+			p.VMS += c.VMS
+			p.ReadRate += c.ReadRate
+			p.WriteRate += c.WriteRate
+			add(c.PID)
+		}
+	}
+	add(p.PID)
+}
+
 func truncateRunes(s string, maxRunes int) string {
 	if maxRunes <= 0 {
 		return ""
@@ -221,27 +311,6 @@ func truncateRunes(s string, maxRunes int) string {
 	return string(r[:maxRunes-3]) + "..."
 }
 
-// stateLabel returns a short display character for a process state.
-func stateLabel(state string) string {
-	switch state {
-	case "running":
-		return "R"
-	case "sleeping", "sleep", "idle":
-		return "S"
-	case "zombie":
-		return "Z"
-	case "stopped", "stop":
-		return "T"
-	case "disk-sleep":
-		return "D"
-	default:
-		if len(state) > 0 {
-			return string([]rune(state)[0:1])
-		}
-		return "?"
-	}
-}
-
 // stateColor returns a color for a process state badge.
 func stateColor(state string) lipgloss.Color {
 	switch state {
@@ -1,8 +1,12 @@
 package ui
 
 import (
+	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
+
+	"github.com/youhide/hideTop/internal/metrics"
 )
 
 func TestTruncateRunes_PreservesUTF8(t *testing.T) {
@@ -23,3 +27,67 @@ func TestTruncateRunes_ShortOrTinyLimit(t *testing.T) {
 		t.Fatalf("tiny limit should trim without ellipsis: got %q", got)
 	}
 }
+
+func TestVisibleProcesses_FollowsSelection(t *testing.T) {
+	procs := make([]metrics.ProcessInfo, 10)
+	for i := range procs {
+		procs[i] = metrics.ProcessInfo{PID: int32(i + 1)}
+	}
+
+	got := VisibleProcesses(procs, ProcessViewState{SelectedIdx: -1}, 3)
+	if len(got) != 3 || got[0].PID != 1 {
+		t.Fatalf("expected first 3 rows without selection, got %v", got)
+	}
+	got = VisibleProcesses(procs, ProcessViewState{SelectedIdx: 7}, 3)
+	if len(got) != 3 || got[0].PID != 6 || got[2].PID != 8 {
+		t.Fatalf("expected rows 6-8 around selection, got %v", got)
+	}
+	if got := VisibleProcesses(procs, ProcessViewState{SelectedIdx: -1}, 0); len(got) != 10 {
+		t.Fatalf("expected every row with no row limit, got %d", len(got))
+	}
+}
+
+func TestNeedsDetails(t *testing.T) {
+	if NeedsDetails(DefaultColumns) {
+		t.Fatalf("default columns should not need process details")
+	}
+	if !NeedsDetails([]string{"pid", "cmd"}) {
+		t.Fatalf("command column needs process details")
+	}
+}
+
+func TestRenderProcesses_MarksTaggedRows(t *testing.T) {
+	procs := []metrics.ProcessInfo{{PID: 10, Name: "runner"}, {PID: 11, Name: "other"}}
+	state := ProcessViewState{SelectedIdx: -1, Tagged: map[int32]bool{10: true}, Columns: []string{"pid", "name"}}
+	out := RenderProcesses(procs, state, 80, 10)
+	if !strings.Contains(out, "[1 tagged]") {
+		t.Fatalf("expected tagged count in header:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "runner") != strings.Contains(line, "●") {
+			t.Fatalf("only the tagged row should be marked: %q", line)
+		}
+	}
+}
+
+func TestRenderProcesses_StartTimeRelativeToSnapshot(t *testing.T) {
+	// A replayed frame from 2024: its processes started "today".
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	procs := []metrics.ProcessInfo{{PID: 10, Name: "runner", CreateTime: now.Add(-3 * time.Hour).UnixMilli()}}
+	state := ProcessViewState{SelectedIdx: -1, Columns: []string{"pid", "start"}, Now: now}
+	if out := RenderProcesses(procs, state, 80, 10); !strings.Contains(out, "09:00") {
+		t.Fatalf("expected the start time as clock time of the snapshot's day:\n%s", out)
+	}
+}
+
+func TestRenderProcessGroups_CountHeader(t *testing.T) {
+	groups := []metrics.ProcessGroup{{Key: "alice", Count: 3}, {Key: "bob", Count: 1}}
+	state := ProcessViewState{SortBy: metrics.SortByPID, SelectedIdx: -1, GroupBy: metrics.GroupUser}
+	if out := RenderProcessGroups(groups, state, 100, 10); !strings.Contains(out, "PROCS▼") {
+		t.Fatalf("expected PROCS marked as the descending sort column:\n%s", out)
+	}
+	state.SortBy = metrics.SortByCPU
+	if out := RenderProcessGroups(groups, state, 100, 10); strings.Contains(out, "PROCS▼") || strings.Contains(out, "PROCS▲") {
+		t.Fatalf("expected no indicator on PROCS when sorting by CPU:\n%s", out)
+	}
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SignalChoice is one entry of the signal menu.
+type SignalChoice struct {
+	Name string // e.g. "HUP"
+	Desc string
+}
+
+// RenderSignalMenu renders the signal menu overlay for the process
+// described by target (e.g. "PID 123 nginx").
+func RenderSignalMenu(choices []SignalChoice, cursor int, target string, width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ColorTitle).Render("Send Signal"))
+	b.WriteString(SubtleStyle.Render("  " + target))
+	b.WriteString("\n\n")
+
+	for i, c := range choices {
+		line := fmt.Sprintf("%s %s  %s",
+			SubtleStyle.Render(fmt.Sprintf("%d", (i+1)%10)),
+			lipgloss.NewStyle().Bold(true).Foreground(ColorHeader).Width(8).Render("SIG"+c.Name),
+			SubtleStyle.Render(c.Desc),
+		)
+		if i == cursor {
+			line = lipgloss.NewStyle().Background(ColorSelectedBg).Render("▎" + line)
+		} else {
+			line = " " + line
+		}
+		b.WriteString(" " + line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SubtleStyle.Render("  Enter or 0-9 choose  │  X or Esc close"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorTitle).
+		Padding(1, 2).
+		Render(b.String())
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
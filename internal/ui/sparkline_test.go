@@ -95,6 +95,46 @@ func TestBuildTreeDisplay_ParentChild(t *testing.T) {
 	}
 }
 
+func TestBuildTree_CollapsedSumsDescendants(t *testing.T) {
+	procs := []metrics.ProcessInfo{
+		{PID: 1, PPID: 0, Name: "init", CPUPercent: 1, NumThreads: 1},
+		{PID: 10, PPID: 1, Name: "bash", CPUPercent: 2, NumThreads: 1},
+		{PID: 11, PPID: 10, Name: "make", CPUPercent: 40, MemPercent: 3, NumThreads: 4},
+		{PID: 20, PPID: 1, Name: "sshd", CPUPercent: 0.5, NumThreads: 2},
+	}
+	result := buildTree(procs, map[int32]bool{10: true})
+	if len(result) != 3 {
+		t.Fatalf("expected make to be hidden under collapsed bash, got %d rows", len(result))
+	}
+	bash := result[1]
+	if bash.proc.PID != 10 || bash.marker != "▸ " {
+		t.Fatalf("expected collapsed bash row, got PID %d marker %q", bash.proc.PID, bash.marker)
+	}
+	if bash.proc.CPUPercent != 42 || bash.proc.MemPercent != 3 || bash.proc.NumThreads != 5 {
+		t.Errorf("expected subtree totals 42%%/3%%/5 threads, got %v/%v/%d",
+			bash.proc.CPUPercent, bash.proc.MemPercent, bash.proc.NumThreads)
+	}
+	if result[0].marker != "▾ " || result[2].marker != "" {
+		t.Errorf("expected expanded marker on init and none on leaf sshd, got %q and %q", result[0].marker, result[2].marker)
+	}
+}
+
+func TestTreeOrder_KeepsSortWithinParent(t *testing.T) {
+	// Sorted by CPU: children must follow that order under their parent.
+	procs := []metrics.ProcessInfo{
+		{PID: 11, PPID: 1, CPUPercent: 50},
+		{PID: 1, PPID: 0, CPUPercent: 1},
+		{PID: 12, PPID: 1, CPUPercent: 5},
+	}
+	got := TreeOrder(procs, nil)
+	want := []int32{1, 11, 12}
+	for i, p := range got {
+		if p.PID != want[i] {
+			t.Fatalf("expected order %v, got PID %d at %d", want, p.PID, i)
+		}
+	}
+}
+
 func TestRenderProcesses_TreeViewHeader(t *testing.T) {
 	state := ProcessViewState{
 		SortBy:      metrics.SortByCPU,
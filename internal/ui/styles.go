@@ -11,6 +11,8 @@ var (
 	ColorBorder     = lipgloss.Color("#3F3F46")
 	ColorHeader     = lipgloss.Color("#D4D4D8")
 	ColorSelectedBg = lipgloss.Color("#3B3B5C")
+	ColorCyan       = lipgloss.Color("#22D3EE")
+	ColorMagenta    = lipgloss.Color("#E879F9")
 )
 
 var (
@@ -19,6 +19,8 @@ type Theme struct {
 	Border     lipgloss.Color
 	Header     lipgloss.Color
 	SelectedBg lipgloss.Color
+	Cyan       lipgloss.Color
+	Magenta    lipgloss.Color
 }
 
 var themes = map[string]Theme{
@@ -32,6 +34,8 @@ var themes = map[string]Theme{
 		Border:     lipgloss.Color("#3F3F46"),
 		Header:     lipgloss.Color("#D4D4D8"),
 		SelectedBg: lipgloss.Color("#3B3B5C"),
+		Cyan:       lipgloss.Color("#22D3EE"),
+		Magenta:    lipgloss.Color("#E879F9"),
 	},
 	"light": {
 		Name:       "light",
@@ -43,6 +47,8 @@ var themes = map[string]Theme{
 		Border:     lipgloss.Color("#D1D5DB"),
 		Header:     lipgloss.Color("#374151"),
 		SelectedBg: lipgloss.Color("#E0E7FF"),
+		Cyan:       lipgloss.Color("#0891B2"),
+		Magenta:    lipgloss.Color("#C026D3"),
 	},
 	"dracula": {
 		Name:       "dracula",
@@ -54,6 +60,8 @@ var themes = map[string]Theme{
 		Border:     lipgloss.Color("#44475A"),
 		Header:     lipgloss.Color("#F8F8F2"),
 		SelectedBg: lipgloss.Color("#44475A"),
+		Cyan:       lipgloss.Color("#8BE9FD"),
+		Magenta:    lipgloss.Color("#FF79C6"),
 	},
 	"nord": {
 		Name:       "nord",
@@ -65,6 +73,8 @@ var themes = map[string]Theme{
 		Border:     lipgloss.Color("#3B4252"),
 		Header:     lipgloss.Color("#ECEFF4"),
 		SelectedBg: lipgloss.Color("#3B4252"),
+		Cyan:       lipgloss.Color("#8FBCBB"),
+		Magenta:    lipgloss.Color("#B48EAD"),
 	},
 	"monokai": {
 		Name:       "monokai",
@@ -76,6 +86,8 @@ var themes = map[string]Theme{
 		Border:     lipgloss.Color("#49483E"),
 		Header:     lipgloss.Color("#F8F8F2"),
 		SelectedBg: lipgloss.Color("#49483E"),
+		Cyan:       lipgloss.Color("#66D9EF"),
+		Magenta:    lipgloss.Color("#FD971F"),
 	},
 }
 
@@ -106,6 +118,8 @@ func ApplyTheme(name string) {
 	ColorBorder = t.Border
 	ColorHeader = t.Header
 	ColorSelectedBg = t.SelectedBg
+	ColorCyan = t.Cyan
+	ColorMagenta = t.Magenta
 
 	// Rebuild derived styles
 	TitleStyle = lipgloss.NewStyle().
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/youhide/hideTop/internal/alert"
 	"github.com/youhide/hideTop/internal/app"
 	"github.com/youhide/hideTop/internal/config"
+	"github.com/youhide/hideTop/internal/exporter"
+	"github.com/youhide/hideTop/internal/record"
+	"github.com/youhide/hideTop/internal/remote"
 	"github.com/youhide/hideTop/internal/ui"
 )
 
@@ -27,13 +35,81 @@ func main() {
 		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
 		slog.SetDefault(slog.New(handler))
 		slog.Debug("debug mode enabled", "version", Version)
+	} else if cfg.Serve != "" || cfg.Agent {
+		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
+		slog.SetDefault(slog.New(handler))
 	} else {
 		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
 	}
 
+	// Headless modes: no TUI.
+	if cfg.Agent {
+		runHeadless(remote.NewAgent(cfg).Serve)
+		return
+	}
+	if cfg.Serve != "" {
+		runHeadless(func(ctx context.Context) error { return exporter.Serve(ctx, cfg) })
+		return
+	}
+
+	if len(cfg.Columns) > 0 {
+		cols, err := ui.ParseColumns(cfg.Columns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hideTop: columns: %v\n", err)
+			os.Exit(2)
+		}
+		cfg.Columns = cols
+	}
+
 	m := app.New(cfg)
 	m.SetVersion(Version)
 
+	if len(cfg.Alerts) > 0 {
+		rules, err := alert.FromConfig(cfg.Alerts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
+			os.Exit(2)
+		}
+		m.SetAlerts(alert.NewEngine(rules))
+	}
+
+	if cfg.Replay != "" && (cfg.Record != "" || cfg.Connect != "") {
+		fmt.Fprintln(os.Stderr, "hideTop: --replay cannot be combined with --record or --connect")
+		os.Exit(2)
+	}
+	var client *remote.Client
+	if cfg.Connect != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		c, err := remote.Dial(ctx, cfg.Connect, cfg.Token)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
+			os.Exit(1)
+		}
+		client = c
+		m.SetRemote(c)
+	}
+	var replay *record.Recording
+	if cfg.Replay != "" {
+		rec, err := record.Open(cfg.Replay)
+		if err == nil {
+			err = m.SetReplay(rec)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
+			os.Exit(1)
+		}
+		replay = rec
+	}
+	if cfg.Record != "" {
+		w, err := record.Create(cfg.Record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
+			os.Exit(1)
+		}
+		m.SetRecorder(w)
+	}
+
 	if cfg.Theme != "" {
 		ui.ApplyTheme(cfg.Theme)
 	}
@@ -43,7 +119,28 @@ func main() {
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	_, err := p.Run()
+	if client != nil {
+		_ = client.Close()
+	}
+	if replay != nil {
+		_ = replay.Close()
+	}
+	if cerr := m.StopRecording(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runHeadless runs fn until SIGINT/SIGTERM and exits non-zero on error.
+func runHeadless(fn func(ctx context.Context) error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := fn(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "hideTop: %v\n", err)
 		os.Exit(1)
 	}